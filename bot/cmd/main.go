@@ -2,16 +2,29 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
+	gostrings "strings"
 	"syscall"
 	"time"
 
+	"bot/internal/content"
 	"bot/internal/discord"
+	"bot/internal/health"
+	"bot/internal/images"
+	"bot/internal/logging"
+	"bot/internal/metrics"
+	"bot/internal/platform/nekosbest"
+	"bot/internal/policy"
 	"bot/internal/scheduler"
+	"bot/internal/screening"
+	"bot/internal/search"
 	"bot/internal/storage/mongodb"
+	"bot/internal/strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/joho/godotenv"
@@ -24,8 +37,6 @@ var STARTTIME time.Time
 var dg *discordgo.Session
 
 func main() {
-	fmt.Println("Cordfriend AI [Version " + VERSION + "]")
-
 	ctx, cancel := context.WithCancel(context.Background())
 
 	defer cancel()
@@ -36,13 +47,18 @@ func main() {
 
 	STARTTIME = time.Now()
 
-	scheduler.StartUptimePingScheduler(STARTTIME, ctx)
-
+	// Without a .env file the settings can still come from the environment,
+	// as they do in containers.
 	err := godotenv.Load()
+
+	logging.Setup()
+
 	if err != nil {
-		log.Fatal("Error while loading .env file:", err)
+		slog.Warn("Could not load .env file", "error", err)
 	}
 
+	slog.Info("Cordfriend AI", "version", VERSION)
+
 	var mongoClient = mongodb.ConnectToMongo()
 
 	var databaseName = "cordfriendAI"
@@ -50,65 +66,662 @@ func main() {
 	var discordToken = os.Getenv("DISCORD_TOKEN")
 
 	if discordToken == "" {
-		log.Fatal("Discord token not set.")
+		slog.Error("Discord token not set")
+		os.Exit(1)
 	}
 
-	fmt.Println("Discord token:", discordToken)
+	imageRegistry := newImageRegistry()
 
 	// Create Discord session
 	var errds error
 	dg, errds = discordgo.New("Bot " + discordToken)
 	if errds != nil {
-		log.Fatal("Error starting Discord session:", err)
+		slog.Error("Error starting Discord session", "error", errds)
+		os.Exit(1)
+	}
+
+	metrics.InstrumentSession(dg)
+
+	metricsAddr := os.Getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = ":9090"
 	}
 
+	healthChecker := health.NewChecker(dg, mongoClient, STARTTIME)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/healthz", healthChecker.Healthz)
+	mux.HandleFunc("/readyz", healthChecker.Readyz)
+
+	httpServer := startHTTPServer(metricsAddr, mux)
+
 	dg.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Printf("Logged in as: %v#%v", s.State.User.Username, s.State.User.Discriminator)
+		slog.Info("Logged in", "user", s.State.User.Username+"#"+s.State.User.Discriminator)
 
 		var minCount float64 = 1.0
+		var categoryDescription = strings.TruncateString("Can be "+gostrings.Join(imageRegistry.Categories(), "/"), 100)
+		var adminPermission = policy.AdminPermission
+		var noBudget float64 = 0.0
 
 		var commands = []*discordgo.ApplicationCommand{
 			{
-				Name:        "load-name",
-				Description: "Sets the nickname to the saved name from the Cordfriend AI dashboard.",
-				Type:        discordgo.ChatApplicationCommand,
+				Name:                     "load-name",
+				Description:              "Sets the nickname to the saved name from the Cordfriend AI dashboard.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
 			},
 			{
 				Name:        "fetch-neko",
-				Description: "Fetches an image of a husbando/kitsune/neko/waifu of your choice and count.",
+				Description: "Fetches images of the category of your choice and count.",
 				Type:        discordgo.ChatApplicationCommand,
 				Options: []*discordgo.ApplicationCommandOption{
 					{
 						Type:        discordgo.ApplicationCommandOptionString,
 						Name:        "type",
-						Description: "Can be husbando/kitsune/neko/waifu",
-						Choices: []*discordgo.ApplicationCommandOptionChoice{
+						Description: categoryDescription,
+						Choices:     imageRegistry.Choices(),
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "qty",
+						Description: "Amount of images to fetch",
+						MinValue:    &minCount,
+						MaxValue:    10.0,
+						Required:    false,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "search",
+						Description:  "Artist to search for",
+						Autocomplete: true,
+						Required:     false,
+					},
+				},
+			},
+			{
+				Name:        "react",
+				Description: "Posts an animated reaction, optionally aimed at another member.",
+				Type:        discordgo.ChatApplicationCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "action",
+						Description:  "Reaction to post, such as hug/pat/wave",
+						Autocomplete: true,
+						Required:     true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "Member to react to",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Name:        "memory",
+				Description: "View, export or clear the bot's conversation history.",
+				Type:        discordgo.ChatApplicationCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows the most recent conversations.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "clear",
+						Description: "Deletes the whole conversation history of this server.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "forget-me",
+						Description: "Removes only your messages from the conversation history.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "clear-legacy",
+						Description: "Deletes the old messages that were stored without their author.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "export",
+						Description: "Exports the conversation history as a file.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "format",
+								Description: "Can be json/markdown",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "json",
+										Value: "json",
+									},
+									{
+										Name:  "markdown",
+										Value: "markdown",
+									},
+								},
+								Required: false,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:        "calc",
+				Description: "Calculates an expression or converts units, such as 2^64 or 5 km to mi.",
+				Type:        discordgo.ChatApplicationCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "expression",
+						Description: "Expression or conversion to calculate",
+						MaxLength:   500,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Name:        "remind",
+				Description: "Schedules reminders that mention you in this channel.",
+				Type:        discordgo.ChatApplicationCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "create",
+						Description: "Creates a reminder.",
+						Options: []*discordgo.ApplicationCommandOption{
 							{
-								Name:  "husbando",
-								Value: "husbando",
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "when",
+								Description: "Delay such as '2 hours' or time such as '18:30'",
+								Required:    true,
 							},
 							{
-								Name:  "kitsune",
-								Value: "kitsune",
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "message",
+								Description: "What to remind you about",
+								MaxLength:   1000,
+								Required:    true,
 							},
 							{
-								Name:  "neko",
-								Value: "neko",
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "timezone",
+								Description: "Place or time zone of the time, UTC by default",
+								Required:    false,
 							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "list",
+						Description: "Shows your pending reminders.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "cancel",
+						Description: "Cancels one of your reminders.",
+						Options: []*discordgo.ApplicationCommandOption{
 							{
-								Name:  "waifu",
-								Value: "waifu",
+								Type:         discordgo.ApplicationCommandOptionString,
+								Name:         "id",
+								Description:  "Reminder to cancel",
+								Autocomplete: true,
+								Required:     true,
 							},
 						},
-						Required: true,
 					},
+				},
+			},
+			{
+				Name:        "privacy",
+				Description: "Controls whether the bot stores and processes your messages.",
+				Type:        discordgo.ChatApplicationCommand,
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Type:        discordgo.ApplicationCommandOptionInteger,
-						Name:        "qty",
-						Description: "Amount of images to fetch",
-						MinValue:    &minCount,
-						MaxValue:    10.0,
-						Required:    false,
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "opt-out",
+						Description: "Stops the bot from storing your messages or sending them to the AI.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "opt-in",
+						Description: "Lets the bot respond to your messages again.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "delete-my-data",
+						Description: "Deletes your stored messages and reminders from every server.",
+					},
+				},
+			},
+			{
+				Name:                     "policy",
+				Description:              "Controls who can use the bot and where.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows the current policy.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "role",
+						Description: "Allows or denies a role.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionRole,
+								Name:        "role",
+								Description: "Role to change",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "access",
+								Description: "Can be allow/deny/default",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "allow",
+										Value: "allow",
+									},
+									{
+										Name:  "deny",
+										Value: "deny",
+									},
+									{
+										Name:  "default",
+										Value: "default",
+									},
+								},
+								Required: true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "channel",
+						Description: "Adds or removes a channel the bot can be used in.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionChannel,
+								Name:        "channel",
+								Description: "Channel to change",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "allowed",
+								Description: "Whether the bot can be used in the channel",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "admin-only",
+						Description: "Limits a command or AI mentions to server admins.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "feature",
+								Description: "Command or feature to limit",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "AI mentions",
+										Value: policy.MentionFeature,
+									},
+									{
+										Name:  "fetch-neko",
+										Value: "fetch-neko",
+									},
+									{
+										Name:  "react",
+										Value: "react",
+									},
+									{
+										Name:  "memory",
+										Value: "memory",
+									},
+									{
+										Name:  "remind",
+										Value: "remind",
+									},
+									{
+										Name:  "calc",
+										Value: "calc",
+									},
+								},
+								Required: true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Description: "Whether only admins can use it",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "mod-log",
+						Description: "Sets the channel moderation actions are logged to.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:         discordgo.ApplicationCommandOptionChannel,
+								Name:         "channel",
+								Description:  "Leave empty to stop logging.",
+								ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "reset",
+						Description: "Removes every restriction.",
+					},
+				},
+			},
+			{
+				Name:                     "image-settings",
+				Description:              "Controls which images can be posted or generated in this server.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows the setting of every image category.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "set",
+						Description: "Enables, disables or limits a category to age-restricted channels.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "category",
+								Description: categoryDescription,
+								Choices:     imageRegistry.Choices(),
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "mode",
+								Description: "Can be enabled/nsfw-only/disabled",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "enabled",
+										Value: content.ModeEnabled,
+									},
+									{
+										Name:  "nsfw-only",
+										Value: content.ModeNSFWOnly,
+									},
+									{
+										Name:  "disabled",
+										Value: content.ModeDisabled,
+									},
+								},
+								Required: true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "generation",
+						Description: "Enables AI image generation and sets its daily limit.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Description: "Whether the AI can generate images",
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "daily-limit",
+								Description: "Images the server can generate per day",
+								MinValue:    &minCount,
+								MaxValue:    100.0,
+								Required:    false,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:                     "search-settings",
+				Description:              "Controls how the AI searches and reads the web.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows the current search provider.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "provider",
+						Description: "Sets the search provider.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "provider",
+								Description: "Can be vyntr/searxng/brave/google",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Vyntr",
+										Value: search.VyntrProvider,
+									},
+									{
+										Name:  "SearXNG",
+										Value: search.SearXNGProvider,
+									},
+									{
+										Name:  "Brave Search",
+										Value: search.BraveProvider,
+									},
+									{
+										Name:  "Google Search",
+										Value: search.GoogleProvider,
+									},
+								},
+								Required: true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "prefetch-links",
+						Description: "Reads links in messages before the AI answers.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Description: "Whether links are read up front",
+								Required:    true,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:                     "usage",
+				Description:              "Shows how much of this server's AI key is used and sets budgets.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows today's and this month's usage.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "budget",
+						Description: "Sets the token budgets. Use 0 for no limit.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "daily-tokens",
+								Description: "Tokens the server can use per day",
+								MinValue:    &noBudget,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionInteger,
+								Name:        "monthly-tokens",
+								Description: "Tokens the server can use per month",
+								MinValue:    &noBudget,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:                     "screening-settings",
+				Description:              "Controls which messages are kept from the AI.",
+				Type:                     discordgo.ChatApplicationCommand,
+				DefaultMemberPermissions: &adminPermission,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "view",
+						Description: "Shows the current screening settings.",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "injection-filter",
+						Description: "Blocks messages that try to override the bot's instructions.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "enabled",
+								Description: "Whether the filter is on",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "blocked-word",
+						Description: "Blocks or unblocks a word or phrase.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "word",
+								Description: "The word or phrase",
+								MaxLength:   100,
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "blocked",
+								Description: "Whether messages containing it are blocked",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "blocked-pattern",
+						Description: "Blocks or unblocks messages matching a regular expression.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "pattern",
+								Description: "The regular expression, matched without case",
+								MaxLength:   200,
+								Required:    true,
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "blocked",
+								Description: "Whether matching messages are blocked",
+								Required:    true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "safety",
+						Description: "Classifies messages with Gemini's safety filters first.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "level",
+								Description: "Can be off/low/medium/high",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Off",
+										Value: "off",
+									},
+									{
+										Name:  "Block low risk and above",
+										Value: screening.SafetyLow,
+									},
+									{
+										Name:  "Block medium risk and above",
+										Value: screening.SafetyMedium,
+									},
+									{
+										Name:  "Block high risk only",
+										Value: screening.SafetyHigh,
+									},
+								},
+								Required: true,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "output",
+						Description: "Controls what is filtered from the AI's replies.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "filter-invites",
+								Description: "Whether server invites are filtered",
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionBoolean,
+								Name:        "filter-mass-mentions",
+								Description: "Whether @everyone and @here are filtered",
+							},
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "mode",
+								Description: "Can be neutralize/strip",
+								Choices: []*discordgo.ApplicationCommandOptionChoice{
+									{
+										Name:  "Neutralize",
+										Value: screening.OutputNeutralize,
+									},
+									{
+										Name:  "Strip",
+										Value: screening.OutputStrip,
+									},
+								},
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionSubCommand,
+						Name:        "reply",
+						Description: "Sets the reply to blocked messages.",
+						Options: []*discordgo.ApplicationCommandOption{
+							{
+								Type:        discordgo.ApplicationCommandOptionString,
+								Name:        "message",
+								Description: "Leave empty for the default reply.",
+								MaxLength:   500,
+							},
+						},
 					},
 				},
 			},
@@ -119,29 +732,54 @@ func main() {
 		for i, v := range commands {
 			cmd, err := s.ApplicationCommandCreate(s.State.User.ID, "", v)
 			if err != nil {
-				log.Panicf("Cannot create '%v' command: %v", v.Name, err)
+				slog.Error("Cannot create command", "command", v.Name, "error", err)
+				panic(err)
 			}
 			registeredCommands[i] = cmd
 		}
 	})
 
 	messageHandler := discord.MessageHandler(mongoClient.Database(databaseName))
-	commandHandler := discord.CommandHandler(mongoClient.Database(databaseName))
+	commandHandler := discord.CommandHandler(mongoClient.Database(databaseName), imageRegistry)
+	componentHandler := discord.ComponentHandler(mongoClient.Database(databaseName))
 
 	dg.AddHandler(commandHandler.HandleCommand)
+	dg.AddHandler(commandHandler.HandleAutocomplete)
+	dg.AddHandler(componentHandler.HandleComponent)
 	dg.AddHandler(messageHandler.HandleMessageCreate)
 
-	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+	dg.Identify.Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+
+	// The members intent is privileged and must also be enabled in the
+	// developer portal. With it, the Discord tools can list who has a role.
+	if os.Getenv("DISCORD_MEMBERS_INTENT") == "true" {
+		dg.Identify.Intents |= discordgo.IntentsGuildMembers
+
+		dg.AddHandler(func(s *discordgo.Session, g *discordgo.GuildCreate) {
+			err := s.RequestGuildMembers(g.ID, "", 0, "", false)
+			if err != nil {
+				slog.Warn("Error while requesting guild members", "guild_id", g.ID, "error", err)
+			}
+		})
+	}
 
 	// Open a websocket to connect to Discord
 	err = dg.Open()
 	if err != nil {
-		fmt.Println("Error opening connection:", err)
+		slog.Error("Error opening connection", "error", err)
 		return
 	}
 
+	scheduler.StartReminderScheduler(ctx, dg, mongodb.NewReminderRepository(mongoClient.Database(databaseName)))
+
+	// Pushing the health report is optional, for setups that cannot scrape
+	// the health endpoints.
+	if serverToPing := os.Getenv("SERVER_TO_PING"); serverToPing != "" {
+		scheduler.StartUptimePingScheduler(ctx, healthChecker, serverToPing, os.Getenv("PING_SECRET"))
+	}
+
 	// Wait here until CTRL-C or other term signal is received.
-	fmt.Println("Bot is now running. Press CTRL-C to exit.")
+	slog.Info("Bot is now running. Press CTRL-C to exit.")
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	<-sc
@@ -149,17 +787,93 @@ func main() {
 	// Cleanly close down the Discord session.
 	dg.Close()
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error shutting down HTTP server", "error", err)
+	}
+
 	// Close MongoDB connection
 	defer func() {
 		if err := mongoClient.Disconnect(context.TODO()); err != nil {
-			log.Println("Error disconnecting from MongoDB:", err)
+			slog.Error("Error disconnecting from MongoDB", "error", err)
 		}
 	}()
 
 	select {
 	case <-sigChan:
-		fmt.Println("Bot shutting down...")
+		slog.Info("Bot shutting down")
 	case <-ctx.Done():
-		fmt.Println("Waiting for services to stop...")
+		slog.Info("Waiting for services to stop")
+	}
+}
+
+// startHTTPServer serves the metrics and health endpoints in the background. A port that
+// cannot be bound is logged, but does not stop the bot.
+func startHTTPServer(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+
+	go func() {
+		slog.Info("HTTP server listening", "addr", addr)
+
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			slog.Error("HTTP server stopped", "error", err)
+		}
+	}()
+
+	return server
+}
+
+// newImageRegistry sets up the image providers named in IMAGE_PROVIDERS, in
+// order of priority, along with the on-disk image cache.
+func newImageRegistry() *images.Registry {
+	var cache *images.DiskCache
+
+	cacheDir := os.Getenv("IMAGE_CACHE_DIR")
+	if cacheDir == "" {
+		cacheDir = filepath.Join(os.TempDir(), "cordfriend-images")
+	}
+
+	cacheSizeMB, err := strconv.Atoi(os.Getenv("IMAGE_CACHE_MAX_MB"))
+	if err != nil {
+		cacheSizeMB = 64
+	}
+
+	if cacheSizeMB > 0 {
+		cache, err = images.NewDiskCache(cacheDir, int64(cacheSizeMB)<<20)
+		if err != nil {
+			slog.Warn("Image cache disabled", "error", err)
+		}
+	}
+
+	providerNames := os.Getenv("IMAGE_PROVIDERS")
+	if providerNames == "" {
+		providerNames = "nekosbest"
+	}
+
+	var providers []images.ImageProvider
+
+	for _, name := range gostrings.Split(providerNames, ",") {
+		switch gostrings.TrimSpace(name) {
+		case "nekosbest":
+			providers = append(providers, nekosbest.NewProvider())
+		case "local":
+			localDir := os.Getenv("LOCAL_IMAGE_DIR")
+			if localDir == "" {
+				slog.Warn("LOCAL_IMAGE_DIR not set, skipping local image provider")
+				continue
+			}
+			providers = append(providers, images.NewLocalProvider(localDir))
+		default:
+			slog.Warn("Unknown image provider", "provider", name)
+		}
+	}
+
+	return images.NewRegistry(images.NewLoader(cache), providers...)
 }
@@ -0,0 +1,112 @@
+// Package calc evaluates arithmetic expressions and unit conversions without
+// calling out to anything, so the results can be trusted where the model's
+// own arithmetic cannot.
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxInputLength keeps expressions to what a person would type.
+const maxInputLength = 500
+
+// Result is an evaluated expression. Unit is set for conversions, and Exact is
+// false when the value shown is rounded.
+type Result struct {
+	Expression string
+	Value      string
+	Unit       string
+	Exact      bool
+}
+
+func (r Result) String() string {
+	if r.Unit != "" {
+		return r.Value + " " + r.Unit
+	}
+
+	return r.Value
+}
+
+// Evaluate calculates an expression such as '2^100', 'sqrt(2) * 3' or a
+// conversion such as '5 km to mi' or '100 °F in °C'.
+func Evaluate(input string) (Result, error) {
+	input = strings.TrimSpace(input)
+	result := Result{Expression: input}
+
+	if input == "" {
+		return result, fmt.Errorf("the expression is empty")
+	}
+	if len(input) > maxInputLength {
+		return result, fmt.Errorf("the expression is longer than %v characters", maxInputLength)
+	}
+
+	if match := conversionPattern.FindStringSubmatch(input); match != nil {
+		from, fromErr := lookupUnit(match[2])
+		to, toErr := lookupUnit(match[3])
+
+		if fromErr == nil && toErr == nil {
+			value := intValue(1)
+			if strings.TrimSpace(match[1]) != "" {
+				var err error
+				value, err = evaluateExpression(match[1])
+				if err != nil {
+					return result, err
+				}
+			}
+
+			converted, err := convert(value, from, to)
+			if err != nil {
+				return result, err
+			}
+
+			result.Value = converted.String()
+			result.Unit = to.symbol
+			result.Exact = converted.isShownExactly()
+
+			return result, nil
+		}
+
+		// Without a known unit, this may still be a plain expression.
+		if _, err := evaluateExpression(input); err != nil {
+			if fromErr != nil {
+				return result, fromErr
+			}
+			return result, toErr
+		}
+	}
+
+	value, err := evaluateExpression(input)
+	if err != nil {
+		return result, err
+	}
+
+	result.Value = value.String()
+	result.Exact = value.isShownExactly()
+
+	return result, nil
+}
+
+func evaluateExpression(input string) (Value, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return Value{}, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	value, err := p.expression()
+	if err != nil {
+		return Value{}, err
+	}
+
+	if t := p.peek(); t.kind != tokenEnd {
+		return Value{}, fmt.Errorf("unexpected '%v'", t.text)
+	}
+
+	if err := value.checkFinite(); err != nil {
+		return Value{}, err
+	}
+
+	return value, nil
+}
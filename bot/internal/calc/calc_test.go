@@ -0,0 +1,118 @@
+package calc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		exact bool
+	}{
+		{"addition", "1 + 2", "3", true},
+		{"multiplication before addition", "2 + 3 * 4", "14", true},
+		{"brackets", "(2 + 3) * 4", "20", true},
+		{"left associative subtraction", "10 - 4 - 3", "3", true},
+		{"left associative division", "100 / 10 / 5", "2", true},
+		{"power before multiplication", "2 * 3^2", "18", true},
+		{"right associative power", "2^3^2", "512", true},
+		{"unary minus after power", "-2^2", "-4", true},
+		{"bracketed negative base", "(-2)^2", "4", true},
+		{"negative exponent", "2^-2", "0.25", true},
+		{"implicit multiplication", "2(3 + 4)", "14", true},
+		{"exact fractions", "1/3 + 1/6", "0.5", true},
+		{"rounded fraction", "1/3", "0.333333333333333", false},
+		{"big integers", "2^100", "1267650600228229401496703205376", true},
+		{"factorial", "5!", "120", true},
+		{"modulo", "7 % 3", "1", true},
+		{"modulo negative dividend", "-7 % 3", "2", true},
+		{"modulo negative divisor", "7 % -3", "-2", true},
+		{"modulo both negative", "-7 % -3", "-1", true},
+		{"modulo fractions", "7.5 % 2", "1.5", false},
+		{"function", "sqrt(16)", "4", false},
+		{"pasted symbols", "6 × 7", "42", true},
+		{"hex literal", "0xff", "255", true},
+		{"scientific notation", "1.5e3", "1500", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Evaluate(tt.input)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) returned an error: %v", tt.input, err)
+			}
+			if result.Value != tt.want {
+				t.Errorf("Evaluate(%q) = %q, want %q", tt.input, result.Value, tt.want)
+			}
+			if result.Unit != "" {
+				t.Errorf("Evaluate(%q) has unit %q, want none", tt.input, result.Unit)
+			}
+			if result.Exact != tt.exact {
+				t.Errorf("Evaluate(%q).Exact = %v, want %v", tt.input, result.Exact, tt.exact)
+			}
+		})
+	}
+}
+
+func TestEvaluateConversions(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+		unit  string
+	}{
+		{"5 km to m", "5000", "m"},
+		{"1 mi in km", "1.609344", "km"},
+		{"2 * 3 kg to g", "6000", "g"},
+		{"100 °F in °C", "37.7777777777778", "°C"},
+		{"0 °C to K", "273.15", "K"},
+		{"1 GiB to MiB", "1024", "MiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := Evaluate(tt.input)
+			if err != nil {
+				t.Fatalf("Evaluate(%q) returned an error: %v", tt.input, err)
+			}
+			if result.Value != tt.value || result.Unit != tt.unit {
+				t.Errorf("Evaluate(%q) = %q %q, want %q %q", tt.input, result.Value, result.Unit, tt.value, tt.unit)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"empty", "   ", "empty"},
+		{"too long", strings.Repeat("1+", maxInputLength) + "1", "longer than"},
+		{"nested too deeply", strings.Repeat("(", maxDepth+1) + "1" + strings.Repeat(")", maxDepth+1), "nested too deeply"},
+		{"factorial limit", "3001!", "factorial is limited"},
+		{"division by zero", "1 / 0", "division by zero"},
+		{"modulo by zero", "5 % 0", "modulo by zero"},
+		{"incompatible units", "5 km to kg", "cannot convert"},
+		{"below absolute zero", "-300 °C to K", "below absolute zero"},
+		{"unknown name", "foo + 1", "unknown name"},
+		{"missing bracket", "(1 + 2", "missing closing bracket"},
+		{"unexpected character", "1 $ 2", "unexpected character"},
+		{"ends too early", "1 +", "ends too early"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Evaluate(tt.input)
+			if err == nil {
+				t.Fatalf("Evaluate(%q) returned no error, want one containing %q", tt.input, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Evaluate(%q) error = %q, want it to contain %q", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var constants = map[string]Value{
+	"pi":  inexact(math.Pi),
+	"tau": inexact(2 * math.Pi),
+	"e":   inexact(math.E),
+	"phi": inexact(math.Phi),
+}
+
+type function struct {
+	args int
+	call func(args []Value) (Value, error)
+}
+
+// float wraps a float64 function of one argument.
+func float(f func(float64) float64) function {
+	return function{args: 1, call: func(args []Value) (Value, error) {
+		return inexact(f(args[0].Float())), nil
+	}}
+}
+
+var functions = map[string]function{
+	"sqrt": {args: 1, call: func(args []Value) (Value, error) {
+		if args[0].Float() < 0 {
+			return Value{}, fmt.Errorf("the square root of a negative number is not real")
+		}
+		return inexact(math.Sqrt(args[0].Float())), nil
+	}},
+	"cbrt":  float(math.Cbrt),
+	"exp":   float(math.Exp),
+	"sin":   float(math.Sin),
+	"cos":   float(math.Cos),
+	"tan":   float(math.Tan),
+	"asin":  float(math.Asin),
+	"acos":  float(math.Acos),
+	"atan":  float(math.Atan),
+	"sinh":  float(math.Sinh),
+	"cosh":  float(math.Cosh),
+	"tanh":  float(math.Tanh),
+	"ln":    logarithm(math.Log),
+	"log":   logarithm(math.Log10),
+	"log10": logarithm(math.Log10),
+	"log2":  logarithm(math.Log2),
+	"deg": {args: 1, call: func(args []Value) (Value, error) {
+		return inexact(args[0].Float() * 180 / math.Pi), nil
+	}},
+	"rad": {args: 1, call: func(args []Value) (Value, error) {
+		return inexact(args[0].Float() * math.Pi / 180), nil
+	}},
+	"abs": {args: 1, call: func(args []Value) (Value, error) {
+		if args[0].IsExact() {
+			return exact(new(big.Rat).Abs(args[0].rat)), nil
+		}
+		return inexact(math.Abs(args[0].float)), nil
+	}},
+	// Rational denominators are positive, so Euclidean division rounds
+	// toward negative infinity.
+	"floor": rounding(math.Floor, func(num, denom *big.Int) *big.Int {
+		return new(big.Int).Div(num, denom)
+	}),
+	"ceil": rounding(math.Ceil, func(num, denom *big.Int) *big.Int {
+		floor := new(big.Int).Div(new(big.Int).Neg(num), denom)
+		return floor.Neg(floor)
+	}),
+	"round": rounding(math.Round, func(num, denom *big.Int) *big.Int {
+		// Halves round away from zero, like math.Round.
+		twice := new(big.Int).Mul(num, big.NewInt(2))
+		twice.Add(twice, new(big.Int).Mul(denom, big.NewInt(int64(num.Sign()))))
+		return twice.Quo(twice, new(big.Int).Mul(denom, big.NewInt(2)))
+	}),
+	"fact": {args: 1, call: func(args []Value) (Value, error) {
+		return factorial(args[0])
+	}},
+	"factorial": {args: 1, call: func(args []Value) (Value, error) {
+		return factorial(args[0])
+	}},
+	"min": {args: -1, call: func(args []Value) (Value, error) {
+		return pick(args, -1)
+	}},
+	"max": {args: -1, call: func(args []Value) (Value, error) {
+		return pick(args, 1)
+	}},
+	"gcd": integers(func(a, b *big.Int) (*big.Int, error) {
+		return new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b)), nil
+	}),
+	"lcm": integers(func(a, b *big.Int) (*big.Int, error) {
+		if a.Sign() == 0 || b.Sign() == 0 {
+			return big.NewInt(0), nil
+		}
+		gcd := new(big.Int).GCD(nil, nil, new(big.Int).Abs(a), new(big.Int).Abs(b))
+		lcm := new(big.Int).Mul(a, b)
+		return lcm.Abs(lcm.Quo(lcm, gcd)), nil
+	}),
+	"ncr": integers(func(n, k *big.Int) (*big.Int, error) {
+		if n.Sign() < 0 || k.Sign() < 0 || n.Cmp(big.NewInt(maxFactorial)) > 0 {
+			return nil, fmt.Errorf("ncr needs whole numbers from 0 to %v", maxFactorial)
+		}
+		if k.Cmp(n) > 0 {
+			return big.NewInt(0), nil
+		}
+		return new(big.Int).Binomial(n.Int64(), k.Int64()), nil
+	}),
+	"npr": integers(func(n, k *big.Int) (*big.Int, error) {
+		if n.Sign() < 0 || k.Sign() < 0 || n.Cmp(big.NewInt(maxFactorial)) > 0 {
+			return nil, fmt.Errorf("npr needs whole numbers from 0 to %v", maxFactorial)
+		}
+		if k.Cmp(n) > 0 {
+			return big.NewInt(0), nil
+		}
+		if k.Sign() == 0 {
+			return big.NewInt(1), nil
+		}
+		return new(big.Int).MulRange(n.Int64()-k.Int64()+1, n.Int64()), nil
+	}),
+}
+
+func logarithm(f func(float64) float64) function {
+	return function{args: 1, call: func(args []Value) (Value, error) {
+		if args[0].Float() <= 0 {
+			return Value{}, fmt.Errorf("logarithms need a number above 0")
+		}
+		return inexact(f(args[0].Float())), nil
+	}}
+}
+
+// rounding keeps exact values exact, using whole divides the numerator by the
+// denominator with the rounding wanted.
+func rounding(f func(float64) float64, whole func(num, denom *big.Int) *big.Int) function {
+	return function{args: 1, call: func(args []Value) (Value, error) {
+		if args[0].IsExact() {
+			return exact(new(big.Rat).SetInt(whole(args[0].rat.Num(), args[0].rat.Denom()))), nil
+		}
+		return inexact(f(args[0].float)), nil
+	}}
+}
+
+func integers(f func(a, b *big.Int) (*big.Int, error)) function {
+	return function{args: 2, call: func(args []Value) (Value, error) {
+		if !args[0].isInt() || !args[1].isInt() {
+			return Value{}, fmt.Errorf("needs whole numbers")
+		}
+
+		result, err := f(args[0].rat.Num(), args[1].rat.Num())
+		if err != nil {
+			return Value{}, err
+		}
+
+		return exact(new(big.Rat).SetInt(result)), nil
+	}}
+}
+
+// pick returns the smallest argument when sign is -1 and the largest when it
+// is 1.
+func pick(args []Value, sign int) (Value, error) {
+	if len(args) == 0 {
+		return Value{}, fmt.Errorf("needs at least one number")
+	}
+
+	best := args[0]
+	for _, arg := range args[1:] {
+		var cmp int
+		if best.IsExact() && arg.IsExact() {
+			cmp = arg.rat.Cmp(best.rat)
+		} else if arg.Float() > best.Float() {
+			cmp = 1
+		} else if arg.Float() < best.Float() {
+			cmp = -1
+		}
+
+		if cmp == sign {
+			best = arg
+		}
+	}
+
+	return best, nil
+}
+
+func callFunction(name string, args []Value) (Value, error) {
+	f, ok := functions[name]
+	if !ok {
+		return Value{}, fmt.Errorf("unknown function '%v'", name)
+	}
+
+	if f.args >= 0 && len(args) != f.args {
+		return Value{}, fmt.Errorf("%v takes %v argument(s), got %v", name, f.args, len(args))
+	}
+
+	result, err := f.call(args)
+	if err != nil {
+		return Value{}, fmt.Errorf("%v: %w", name, err)
+	}
+
+	if err := result.checkFinite(); err != nil {
+		return Value{}, fmt.Errorf("%v: %w", name, err)
+	}
+
+	return result, nil
+}
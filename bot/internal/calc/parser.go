@@ -0,0 +1,366 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// symbolReplacer maps the symbols people paste from elsewhere to the
+// operators the parser knows.
+var symbolReplacer = strings.NewReplacer("**", "^", "×", "*", "·", "*", "÷", "/", "−", "-", "π", "pi")
+
+func tokenize(input string) ([]token, error) {
+	input = symbolReplacer.Replace(input)
+	runes := []rune(input)
+
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			if r == '0' && i+1 < len(runes) && strings.ContainsRune("xXbBoO", runes[i+1]) {
+				i += 2
+				for i < len(runes) && (isHexDigit(runes[i]) || runes[i] == '_') {
+					i++
+				}
+			} else {
+				for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+					i++
+				}
+				// An exponent only follows when digits come after the e, so
+				// that 2e still reads as 2 times e.
+				if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+					j := i + 1
+					if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+						j++
+					}
+					if j < len(runes) && unicode.IsDigit(runes[j]) {
+						i = j
+						for i < len(runes) && unicode.IsDigit(runes[i]) {
+							i++
+						}
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: strings.ReplaceAll(string(runes[start:i]), "_", "")})
+		case unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(string(runes[start:i]))})
+		case strings.ContainsRune("+-*/%^!(),", r):
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c'", r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd}), nil
+}
+
+func isHexDigit(r rune) bool {
+	return unicode.IsDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// maxExponent is the largest power of ten written in scientific notation that
+// is kept exact.
+const maxExponent = 1000
+
+// maxDepth limits nesting so deeply nested input cannot exhaust the stack.
+const maxDepth = 100
+
+// parser evaluates while it parses, by recursive descent:
+//
+//	expression = term { ("+" | "-") term }
+//	term       = unary { ("*" | "/" | "%" | implicit) unary }
+//	unary      = ("-" | "+") unary | power
+//	power      = postfix [ "^" unary ]
+//	postfix    = primary { "!" }
+//	primary    = number | name [ "(" arguments ")" ] | "(" expression ")"
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(text string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.text == text
+}
+
+func (p *parser) expression() (Value, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return Value{}, fmt.Errorf("the expression is nested too deeply")
+	}
+
+	left, err := p.term()
+	if err != nil {
+		return Value{}, err
+	}
+
+	for p.isOperator("+") || p.isOperator("-") {
+		op := p.next().text
+
+		right, err := p.term()
+		if err != nil {
+			return Value{}, err
+		}
+
+		if op == "+" {
+			left = add(left, right)
+		} else {
+			left = sub(left, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *parser) term() (Value, error) {
+	left, err := p.unary()
+	if err != nil {
+		return Value{}, err
+	}
+
+	for {
+		t := p.peek()
+
+		var op string
+		switch {
+		case p.isOperator("*") || p.isOperator("/") || p.isOperator("%"):
+			op = p.next().text
+		case t.kind == tokenNumber || t.kind == tokenIdent || p.isOperator("("):
+			// Implicit multiplication, as in 2pi or 3(4 + 5).
+			op = "*"
+		default:
+			return left, nil
+		}
+
+		right, err := p.unary()
+		if err != nil {
+			return Value{}, err
+		}
+
+		switch op {
+		case "*":
+			left = mul(left, right)
+		case "/":
+			left, err = div(left, right)
+		case "%":
+			left, err = mod(left, right)
+		}
+		if err != nil {
+			return Value{}, err
+		}
+	}
+}
+
+func (p *parser) unary() (Value, error) {
+	if p.isOperator("-") || p.isOperator("+") {
+		op := p.next().text
+
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > maxDepth {
+			return Value{}, fmt.Errorf("the expression is nested too deeply")
+		}
+
+		value, err := p.unary()
+		if err != nil {
+			return Value{}, err
+		}
+
+		if op == "-" {
+			return neg(value), nil
+		}
+		return value, nil
+	}
+
+	return p.power()
+}
+
+func (p *parser) power() (Value, error) {
+	base, err := p.postfix()
+	if err != nil {
+		return Value{}, err
+	}
+
+	if !p.isOperator("^") {
+		return base, nil
+	}
+	p.next()
+
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxDepth {
+		return Value{}, fmt.Errorf("the expression is nested too deeply")
+	}
+
+	exponent, err := p.unary()
+	if err != nil {
+		return Value{}, err
+	}
+
+	return pow(base, exponent)
+}
+
+func (p *parser) postfix() (Value, error) {
+	value, err := p.primary()
+	if err != nil {
+		return Value{}, err
+	}
+
+	for p.isOperator("!") {
+		p.next()
+
+		value, err = factorial(value)
+		if err != nil {
+			return Value{}, err
+		}
+	}
+
+	return value, nil
+}
+
+func (p *parser) primary() (Value, error) {
+	t := p.next()
+
+	switch {
+	case t.kind == tokenNumber:
+		return parseNumber(t.text)
+	case t.kind == tokenIdent:
+		if p.isOperator("(") {
+			p.next()
+
+			args, err := p.arguments()
+			if err != nil {
+				return Value{}, err
+			}
+
+			return callFunction(t.text, args)
+		}
+
+		if value, ok := constants[t.text]; ok {
+			return value, nil
+		}
+
+		if _, ok := functions[t.text]; ok {
+			return Value{}, fmt.Errorf("%v needs its arguments in brackets, such as %v(2)", t.text, t.text)
+		}
+
+		return Value{}, fmt.Errorf("unknown name '%v'", t.text)
+	case t.kind == tokenOperator && t.text == "(":
+		value, err := p.expression()
+		if err != nil {
+			return Value{}, err
+		}
+
+		if !p.isOperator(")") {
+			return Value{}, fmt.Errorf("missing closing bracket")
+		}
+		p.next()
+
+		return value, nil
+	case t.kind == tokenEnd:
+		return Value{}, fmt.Errorf("the expression ends too early")
+	}
+
+	return Value{}, fmt.Errorf("unexpected '%v'", t.text)
+}
+
+func (p *parser) arguments() ([]Value, error) {
+	var args []Value
+
+	if p.isOperator(")") {
+		p.next()
+		return args, nil
+	}
+
+	for {
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+
+		if p.isOperator(",") {
+			p.next()
+			continue
+		}
+		if p.isOperator(")") {
+			p.next()
+			return args, nil
+		}
+
+		return nil, fmt.Errorf("missing closing bracket")
+	}
+}
+
+func parseNumber(text string) (Value, error) {
+	if len(text) > 2 && text[0] == '0' && strings.ContainsRune("xXbBoO", rune(text[1])) {
+		n, ok := new(big.Int).SetString(text, 0)
+		if !ok {
+			return Value{}, fmt.Errorf("invalid number '%v'", text)
+		}
+		return exact(new(big.Rat).SetInt(n)), nil
+	}
+
+	// Huge exponents such as 1e999999999 are kept out of exact arithmetic,
+	// which would have to write out every digit.
+	if idx := strings.IndexAny(text, "eE"); idx >= 0 {
+		exponent, err := strconv.Atoi(text[idx+1:])
+		if err != nil || exponent > maxExponent || exponent < -maxExponent {
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil || math.IsInf(f, 0) {
+				return Value{}, fmt.Errorf("the number '%v' is too large", text)
+			}
+			return inexact(f), nil
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return Value{}, fmt.Errorf("invalid number '%v'", text)
+	}
+
+	return exact(rat), nil
+}
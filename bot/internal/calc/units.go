@@ -0,0 +1,188 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// unit converts to the base unit of its category as base = value*factor +
+// offset. Only temperatures have an offset.
+type unit struct {
+	symbol   string
+	category string
+	factor   *big.Rat
+	offset   *big.Rat
+}
+
+func rat(value string) *big.Rat {
+	r, ok := new(big.Rat).SetString(value)
+	if !ok {
+		panic("invalid unit factor " + value)
+	}
+	return r
+}
+
+// unitTable lists each unit as category, symbol, factor to the category's
+// base unit, and other names it is known by. Symbols are case-sensitive so
+// that Mb (megabits) and MB (megabytes) differ, while the other names are
+// matched in any case.
+var unitTable = []struct {
+	category string
+	symbol   string
+	factor   string
+	names    []string
+}{
+	{"length", "m", "1", []string{"meter", "meters", "metre", "metres"}},
+	{"length", "km", "1000", []string{"kilometer", "kilometers", "kilometre", "kilometres"}},
+	{"length", "cm", "1/100", []string{"centimeter", "centimeters", "centimetre", "centimetres"}},
+	{"length", "mm", "1/1000", []string{"millimeter", "millimeters", "millimetre", "millimetres"}},
+	{"length", "µm", "1/1000000", []string{"um", "micrometer", "micrometers", "micron", "microns"}},
+	{"length", "nm", "1/1000000000", []string{"nanometer", "nanometers"}},
+	{"length", "mi", "1609.344", []string{"mile", "miles"}},
+	{"length", "yd", "0.9144", []string{"yard", "yards"}},
+	{"length", "ft", "0.3048", []string{"foot", "feet"}},
+	{"length", "in", "0.0254", []string{"inch", "inches"}},
+	{"length", "nmi", "1852", []string{"nauticalmile", "nauticalmiles"}},
+	{"length", "au", "149597870700", []string{"astronomicalunit", "astronomicalunits"}},
+	{"length", "ly", "9460730472580800", []string{"lightyear", "lightyears"}},
+
+	{"mass", "kg", "1", []string{"kilogram", "kilograms", "kilo", "kilos"}},
+	{"mass", "g", "1/1000", []string{"gram", "grams"}},
+	{"mass", "mg", "1/1000000", []string{"milligram", "milligrams"}},
+	{"mass", "t", "1000", []string{"tonne", "tonnes", "ton", "tons"}},
+	{"mass", "lb", "0.45359237", []string{"lbs", "pound", "pounds"}},
+	{"mass", "oz", "0.028349523125", []string{"ounce", "ounces"}},
+	{"mass", "st", "6.35029318", []string{"stone", "stones"}},
+
+	{"temperature", "K", "1", []string{"kelvin"}},
+	{"temperature", "°C", "1", []string{"c", "degc", "celsius", "centigrade"}},
+	{"temperature", "°F", "5/9", []string{"f", "degf", "fahrenheit"}},
+	{"temperature", "°R", "5/9", []string{"degr", "rankine"}},
+
+	{"data", "bit", "1/8", []string{"bits", "b"}},
+	{"data", "B", "1", []string{"byte", "bytes"}},
+	{"data", "kB", "1000", []string{"kb", "kilobyte", "kilobytes"}},
+	{"data", "MB", "1000000", []string{"mb", "megabyte", "megabytes"}},
+	{"data", "GB", "1000000000", []string{"gb", "gigabyte", "gigabytes"}},
+	{"data", "TB", "1000000000000", []string{"tb", "terabyte", "terabytes"}},
+	{"data", "PB", "1000000000000000", []string{"pb", "petabyte", "petabytes"}},
+	{"data", "KiB", "1024", []string{"kib", "kibibyte", "kibibytes"}},
+	{"data", "MiB", "1048576", []string{"mib", "mebibyte", "mebibytes"}},
+	{"data", "GiB", "1073741824", []string{"gib", "gibibyte", "gibibytes"}},
+	{"data", "TiB", "1099511627776", []string{"tib", "tebibyte", "tebibytes"}},
+	{"data", "PiB", "1125899906842624", []string{"pib", "pebibyte", "pebibytes"}},
+	{"data", "kbit", "125", []string{"Kb", "kilobit", "kilobits"}},
+	{"data", "Mbit", "125000", []string{"Mb", "megabit", "megabits"}},
+	{"data", "Gbit", "125000000", []string{"Gb", "gigabit", "gigabits"}},
+	{"data", "Tbit", "125000000000", []string{"Tb", "terabit", "terabits"}},
+
+	{"volume", "l", "1", []string{"L", "liter", "liters", "litre", "litres"}},
+	{"volume", "ml", "1/1000", []string{"mL", "milliliter", "milliliters", "millilitre", "millilitres"}},
+	{"volume", "cl", "1/100", []string{"cL", "centiliter", "centiliters", "centilitre", "centilitres"}},
+	{"volume", "dl", "1/10", []string{"dL", "deciliter", "deciliters", "decilitre", "decilitres"}},
+	{"volume", "m³", "1000", []string{"m3", "cubicmeter", "cubicmeters", "cubicmetre", "cubicmetres"}},
+	{"volume", "cm³", "1/1000", []string{"cm3", "cc"}},
+	{"volume", "gal", "3.785411784", []string{"gallon", "gallons"}},
+	{"volume", "impgal", "4.54609", []string{"imperialgallon", "imperialgallons"}},
+	{"volume", "qt", "0.946352946", []string{"quart", "quarts"}},
+	{"volume", "pt", "0.473176473", []string{"pint", "pints"}},
+	{"volume", "cup", "0.2365882365", []string{"cups"}},
+	{"volume", "floz", "0.0295735295625", []string{"fluidounce", "fluidounces"}},
+	{"volume", "tbsp", "0.01478676478125", []string{"tablespoon", "tablespoons"}},
+	{"volume", "tsp", "0.00492892159375", []string{"teaspoon", "teaspoons"}},
+
+	{"time", "ms", "1/1000", []string{"millisecond", "milliseconds"}},
+	{"time", "s", "1", []string{"sec", "secs", "second", "seconds"}},
+	{"time", "min", "60", []string{"mins", "minute", "minutes"}},
+	{"time", "h", "3600", []string{"hr", "hrs", "hour", "hours"}},
+	{"time", "d", "86400", []string{"day", "days"}},
+	{"time", "wk", "604800", []string{"week", "weeks"}},
+	{"time", "yr", "31557600", []string{"year", "years"}},
+
+	{"speed", "m/s", "1", []string{"mps"}},
+	{"speed", "km/h", "5/18", []string{"kmh", "kph"}},
+	{"speed", "mph", "0.44704", []string{"mi/h"}},
+	{"speed", "kn", "463/900", []string{"kt", "knot", "knots"}},
+	{"speed", "ft/s", "0.3048", []string{"fps"}},
+}
+
+// temperatureOffsets convert to kelvin together with the factors above.
+var temperatureOffsets = map[string]string{
+	"°C": "273.15",
+	"°F": "45967/180",
+	"°R": "0",
+}
+
+var (
+	unitsBySymbol = map[string]*unit{}
+	unitsByName   = map[string]*unit{}
+)
+
+func init() {
+	for _, entry := range unitTable {
+		u := &unit{
+			symbol:   entry.symbol,
+			category: entry.category,
+			factor:   rat(entry.factor),
+			offset:   new(big.Rat),
+		}
+		if offset, ok := temperatureOffsets[entry.symbol]; ok {
+			u.offset = rat(offset)
+		}
+
+		unitsBySymbol[entry.symbol] = u
+		for _, name := range entry.names {
+			// Names with capitals are symbols in their own right.
+			if strings.ToLower(name) != name {
+				unitsBySymbol[name] = u
+			} else {
+				unitsByName[name] = u
+			}
+		}
+	}
+}
+
+func lookupUnit(name string) (*unit, error) {
+	if u, ok := unitsBySymbol[name]; ok {
+		return u, nil
+	}
+
+	lower := strings.ToLower(strings.TrimPrefix(name, "°"))
+	lower = strings.TrimPrefix(lower, "deg")
+	if u, ok := unitsByName[lower]; ok {
+		return u, nil
+	}
+	if u, ok := unitsByName["deg"+lower]; ok {
+		return u, nil
+	}
+	if u, ok := unitsBySymbol[lower]; ok {
+		return u, nil
+	}
+
+	return nil, fmt.Errorf("unknown unit '%v'", name)
+}
+
+const unitPattern = `([A-Za-zµ°][A-Za-z0-9µ°/²³]*)`
+
+var conversionPattern = regexp.MustCompile(`^(.*?)\s*` + unitPattern + `\s+(?:to|in|as|into)\s+` + unitPattern + `\s*$`)
+
+// convert converts the value from one unit to another of the same kind.
+func convert(value Value, from *unit, to *unit) (Value, error) {
+	if from.category != to.category {
+		return Value{}, fmt.Errorf("cannot convert %v (%v) to %v (%v)", from.symbol, from.category, to.symbol, to.category)
+	}
+
+	base := add(mul(value, exact(from.factor)), exact(from.offset))
+	result, err := div(sub(base, exact(to.offset)), exact(to.factor))
+	if err != nil {
+		return Value{}, err
+	}
+
+	if from.category == "temperature" && base.Float() < 0 {
+		return Value{}, fmt.Errorf("%v %v is below absolute zero", value, from.symbol)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,221 @@
+package calc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// maxExactBits caps the size of exact results, so that something like
+// 9^9^9 falls back to floating point instead of using all memory.
+const maxExactBits = 1 << 16
+
+// Value is a number that stays an exact rational for as long as the
+// operations on it allow, and becomes a float64 otherwise.
+type Value struct {
+	rat   *big.Rat
+	float float64
+}
+
+func exact(rat *big.Rat) Value {
+	return Value{rat: rat}
+}
+
+func inexact(float float64) Value {
+	return Value{float: float}
+}
+
+func intValue(n int64) Value {
+	return exact(new(big.Rat).SetInt64(n))
+}
+
+func (v Value) IsExact() bool {
+	return v.rat != nil
+}
+
+func (v Value) isInt() bool {
+	return v.rat != nil && v.rat.IsInt()
+}
+
+func (v Value) Float() float64 {
+	if v.rat != nil {
+		f, _ := v.rat.Float64()
+		return f
+	}
+
+	return v.float
+}
+
+func (v Value) checkFinite() error {
+	if v.rat == nil && (math.IsInf(v.float, 0) || math.IsNaN(v.float)) {
+		return fmt.Errorf("the result is not a finite number")
+	}
+
+	return nil
+}
+
+func add(a Value, b Value) Value {
+	if a.IsExact() && b.IsExact() {
+		return exact(new(big.Rat).Add(a.rat, b.rat))
+	}
+
+	return inexact(a.Float() + b.Float())
+}
+
+func sub(a Value, b Value) Value {
+	if a.IsExact() && b.IsExact() {
+		return exact(new(big.Rat).Sub(a.rat, b.rat))
+	}
+
+	return inexact(a.Float() - b.Float())
+}
+
+func mul(a Value, b Value) Value {
+	if a.IsExact() && b.IsExact() {
+		return exact(new(big.Rat).Mul(a.rat, b.rat))
+	}
+
+	return inexact(a.Float() * b.Float())
+}
+
+func div(a Value, b Value) (Value, error) {
+	if b.Float() == 0 && (b.IsExact() || b.float == 0) {
+		return Value{}, fmt.Errorf("division by zero")
+	}
+
+	if a.IsExact() && b.IsExact() {
+		return exact(new(big.Rat).Quo(a.rat, b.rat)), nil
+	}
+
+	return inexact(a.Float() / b.Float()), nil
+}
+
+func mod(a Value, b Value) (Value, error) {
+	if b.Float() == 0 {
+		return Value{}, fmt.Errorf("modulo by zero")
+	}
+
+	if a.isInt() && b.isInt() {
+		// Like most calculators, the result takes the sign of the divisor.
+		result := new(big.Int).Rem(a.rat.Num(), b.rat.Num())
+		if result.Sign() != 0 && result.Sign() != b.rat.Num().Sign() {
+			result.Add(result, b.rat.Num())
+		}
+		return exact(new(big.Rat).SetInt(result)), nil
+	}
+
+	result := math.Mod(a.Float(), b.Float())
+	if result != 0 && (result < 0) != (b.Float() < 0) {
+		result += b.Float()
+	}
+
+	return inexact(result), nil
+}
+
+func neg(a Value) Value {
+	if a.IsExact() {
+		return exact(new(big.Rat).Neg(a.rat))
+	}
+
+	return inexact(-a.float)
+}
+
+// pow raises a to b, exactly when b is an integer and the result is not too
+// large.
+func pow(a Value, b Value) (Value, error) {
+	if a.IsExact() && b.isInt() && b.rat.Num().IsInt64() {
+		exponent := b.rat.Num().Int64()
+
+		if a.rat.Sign() == 0 {
+			if exponent < 0 {
+				return Value{}, fmt.Errorf("division by zero")
+			}
+			if exponent == 0 {
+				return intValue(1), nil
+			}
+			return intValue(0), nil
+		}
+
+		size := int64(max(a.rat.Num().BitLen(), a.rat.Denom().BitLen()))
+		magnitude := exponent
+		if magnitude < 0 {
+			magnitude = -magnitude
+		}
+
+		if size*magnitude <= maxExactBits {
+			e := big.NewInt(magnitude)
+			num := new(big.Int).Exp(a.rat.Num(), e, nil)
+			denom := new(big.Int).Exp(a.rat.Denom(), e, nil)
+
+			if exponent < 0 {
+				num, denom = denom, num
+			}
+
+			return exact(new(big.Rat).SetFrac(num, denom)), nil
+		}
+	}
+
+	result := math.Pow(a.Float(), b.Float())
+	if math.IsNaN(result) {
+		return Value{}, fmt.Errorf("%v to the power of %v is not a real number", a, b)
+	}
+
+	return inexact(result), nil
+}
+
+// maxFactorial keeps factorials within the exact size limit.
+const maxFactorial = 3000
+
+func factorial(a Value) (Value, error) {
+	if !a.isInt() || a.rat.Sign() < 0 {
+		return Value{}, fmt.Errorf("factorial needs a whole number of at least 0")
+	}
+	if a.rat.Num().Cmp(big.NewInt(maxFactorial)) > 0 {
+		return Value{}, fmt.Errorf("factorial is limited to %v!", maxFactorial)
+	}
+
+	result := new(big.Int).MulRange(1, a.rat.Num().Int64())
+	if a.rat.Sign() == 0 {
+		result.SetInt64(1)
+	}
+
+	return exact(new(big.Rat).SetInt(result)), nil
+}
+
+// maxDigits is the longest whole number written out in full. Longer ones are
+// written in scientific notation.
+const maxDigits = 1000
+
+// String formats the value with up to 15 significant digits, or in full for
+// exact whole numbers.
+func (v Value) String() string {
+	if v.rat == nil {
+		if v.float == 0 {
+			return "0"
+		}
+		return formatFloat(big.NewFloat(v.float))
+	}
+
+	if v.rat.IsInt() {
+		digits := v.rat.Num().String()
+		if len(digits) <= maxDigits {
+			return digits
+		}
+	}
+
+	return formatFloat(new(big.Float).SetPrec(256).SetRat(v.rat))
+}
+
+// isShownExactly reports whether String shows the value without rounding.
+func (v Value) isShownExactly() bool {
+	if v.rat == nil {
+		return false
+	}
+
+	shown, ok := new(big.Rat).SetString(v.String())
+	return ok && shown.Cmp(v.rat) == 0
+}
+
+func formatFloat(f *big.Float) string {
+	return f.Text('g', 15)
+}
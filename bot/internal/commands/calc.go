@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"bot/internal/calc"
+	"bot/internal/response"
+	"bot/internal/strings"
+	"fmt"
+	gostrings "strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func HandleCalc(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var expression string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "expression" {
+			expression = opt.StringValue()
+		}
+	}
+
+	result, err := calc.Evaluate(expression)
+	if err != nil {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Could not calculate `%v`: %v", escapeCode(expression), err))
+	}
+
+	sign := "="
+	if !result.Exact {
+		sign = "≈"
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: strings.TruncateString(fmt.Sprintf("`%v` %v **%v**", escapeCode(result.Expression), sign, result.String()), 2000),
+		},
+	})
+}
+
+// escapeCode keeps backticks in user input from closing an inline code span.
+func escapeCode(text string) string {
+	return gostrings.ReplaceAll(text, "`", "ˋ")
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bot/internal/logging"
 	"bot/internal/response"
 	"bot/internal/storage/mongodb"
 	"fmt"
@@ -15,7 +16,7 @@ func UpdateBotNickname(s *discordgo.Session, guildID string, i *discordgo.Intera
 		return err
 	}
 
-	fmt.Println("Update nickname command called.")
+	logging.ForInteraction(i).Info("Update nickname command called")
 
 	botRepository := mongodb.NewBotRepository(db)
 
@@ -27,7 +28,7 @@ func UpdateBotNickname(s *discordgo.Session, guildID string, i *discordgo.Intera
 		return nil
 	}
 
-	fmt.Println("Changing nickname for guild ID:", guildID)
+	logging.ForInteraction(i).Info("Changing nickname")
 
 	err = s.GuildMemberNickname(guildID, "@me", nickname)
 	if err != nil {
@@ -40,7 +41,7 @@ func UpdateBotNickname(s *discordgo.Session, guildID string, i *discordgo.Intera
 		Content: &responseMessage,
 	})
 	if err != nil {
-		fmt.Println("Failed to respond to interaction:", err)
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
 	}
 
 	return nil
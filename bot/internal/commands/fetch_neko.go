@@ -1,21 +1,25 @@
 package commands
 
 import (
+	"bot/internal/content"
+	"bot/internal/images"
+	"bot/internal/logging"
 	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 	"strings"
 
-	nb "github.com/Yakiyo/nekos_best.go"
 	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate) error {
-	err := response.DeferResponse(s, i, "Please wait while we fetch the images...")
-	if err != nil {
-		return err
-	}
+// maxUploadBytes keeps the attachments of one reply under Discord's upload
+// limit for servers without boosts.
+const maxUploadBytes = 10 << 20
 
+func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database, registry *images.Registry) error {
 	interactionData := i.ApplicationCommandData()
 
 	options := interactionData.Options
@@ -27,6 +31,7 @@ func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 
 	var imageType string
 	var imageCount int
+	var search string
 
 	if opt, ok := optionMap["type"]; ok {
 		imageType = strings.ToLower(opt.StringValue())
@@ -36,6 +41,20 @@ func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		imageCount = int(opt.IntValue())
 	}
 
+	if opt, ok := optionMap["search"]; ok {
+		search = strings.TrimSpace(opt.StringValue())
+	}
+
+	refused, err := checkContentPolicy(s, i, db, imageType, registry.IsSafe(imageType))
+	if refused || err != nil {
+		return err
+	}
+
+	err = response.DeferResponse(s, i, "Please wait while we fetch the images...")
+	if err != nil {
+		return err
+	}
+
 	if imageCount > 10 {
 		return fmt.Errorf("Please enter valid image count to generate (1 - 10 images).")
 	}
@@ -44,52 +63,174 @@ func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		imageCount = 1
 	}
 
-	switch imageType {
-	case "husbando":
-	case "kitsune":
-	case "neko":
-	case "waifu":
-		fmt.Println("Valid image type.")
-	default:
-		fmt.Printf("Image type '%v' inavid.\n", imageType)
+	provider := registry.Provider(imageType)
+	if provider == nil {
+		logging.ForInteraction(i).Info("Invalid image type", "type", imageType)
 		return fmt.Errorf("Image type '%v' inavid.", imageType)
 	}
 
-	res, err := nb.FetchMany(imageType, imageCount)
+	var res []images.Image
+
+	ctx := logging.InteractionContext(i)
+
+	if search != "" {
+		searcher, ok := provider.(images.Searcher)
+		if !ok {
+			return fmt.Errorf("Searching is not supported for %v.", imageType)
+		}
+		res, err = searcher.Search(ctx, search, imageType, imageCount)
+	} else {
+		res, err = provider.Fetch(ctx, imageType, imageCount)
+	}
 
 	if err != nil {
-		fmt.Println("Error while fetching images:", err)
+		logging.ForInteraction(i).Error("Error while fetching images", "error", err)
 		return fmt.Errorf("Failed to fetch %v: %v", imageType, err)
 	}
 
-	imageUrls := make([]string, 0, len(res))
+	if len(res) == 0 {
+		return fmt.Errorf("No %v images found for '%v'.", imageType, search)
+	}
+
+	files, embeds, failed := renderImages(ctx, registry.Loader, imageType, "", res)
+
+	if len(files) == 0 {
+		return fmt.Errorf("None of the images could be fetched, please try again.")
+	}
+
+	responseMessage := fmt.Sprintf("%v fetched successfully from %v", imageType, provider.Name())
+	if failed > 0 {
+		responseMessage += fmt.Sprintf(" (%v of %v images could not be fetched)", failed, len(res))
+	}
 
-	for _, url := range res {
-		fmt.Println("Fetched URL:", url.Url)
-		imageUrls = append(imageUrls, url.Url)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Files:   files,
+		Embeds:  &embeds,
+		Content: &responseMessage,
+	})
+	if err != nil {
+		return fmt.Errorf("An error occurred while fetching images: %v", err)
 	}
 
-	files := make([]*discordgo.File, 0, len(imageUrls))
-	embeds := make([]*discordgo.MessageEmbed, 0, len(imageUrls))
+	return nil
+}
+
+// AutocompleteNekoSearch suggests artist names for the search option by
+// searching nekos.best with what has been typed so far.
+func AutocompleteNekoSearch(s *discordgo.Session, i *discordgo.InteractionCreate, registry *images.Registry) error {
+	imageType := "neko"
+	var query string
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "type":
+			imageType = strings.ToLower(opt.StringValue())
+		case "search":
+			query = strings.TrimSpace(opt.StringValue())
+		}
+	}
 
-	for idx, url := range imageUrls {
-		resp, err := http.Get(url)
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+
+	searcher, ok := registry.Provider(imageType).(images.Searcher)
+
+	if len(query) >= 2 && ok {
+		res, err := searcher.Search(logging.InteractionContext(i), query, imageType, 20)
 		if err != nil {
-			return fmt.Errorf("An error occurred while fetching images: %v", err)
+			logging.ForInteraction(i).Error("Error while searching for autocomplete", "error", err)
 		}
-		defer resp.Body.Close()
 
-		fileName := fmt.Sprintf("embed_%v.png", idx)
+		seen := make(map[string]bool)
+		for _, result := range res {
+			if result.Artist == "" || seen[result.Artist] || len(choices) == 25 {
+				continue
+			}
+			seen[result.Artist] = true
+
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncateChoice(result.Artist),
+				Value: truncateChoice(result.Artist),
+			})
+		}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+func truncateChoice(value string) string {
+	runes := []rune(value)
+	if len(runes) > 100 {
+		return string(runes[:100])
+	}
+
+	return value
+}
+
+// checkContentPolicy refuses the interaction with an explanation when the
+// guild's content policy does not allow the category in this channel.
+func checkContentPolicy(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database, category string, safe bool) (bool, error) {
+	if i.GuildID == "" {
+		return false, nil
+	}
+
+	contentPolicy, err := mongodb.NewBotRepository(db).FetchContentPolicy(i.GuildID)
+	if err != nil {
+		return true, response.RespondEphemeral(s, i, fmt.Sprintf("Could not load the image settings for this server: %v", err))
+	}
+
+	err = content.CheckImageCategory(s, contentPolicy, category, safe, i.ChannelID)
+	if err != nil {
+		logging.ForInteraction(i).Info("Returning as policy refuses the image category", "category", category, "reason", err)
+		return true, response.RespondEphemeral(s, i, err.Error())
+	}
+
+	return false, nil
+}
+
+// renderImages loads the results and builds an embed with attribution for
+// each one that succeeded, prefixed by description when it is set. It also
+// returns how many results could not be loaded.
+func renderImages(ctx context.Context, loader *images.Loader, title string, description string, res []images.Image) ([]*discordgo.File, []*discordgo.MessageEmbed, int) {
+	loaded := loader.Load(ctx, res)
+
+	files := make([]*discordgo.File, 0, len(loaded))
+	embeds := make([]*discordgo.MessageEmbed, 0, len(loaded))
+
+	var uploadSize int64
+	var failed int
+
+	for idx, image := range loaded {
+		if image.Err == nil && uploadSize+int64(len(image.Data)) > maxUploadBytes {
+			image.Err = fmt.Errorf("upload limit reached")
+		}
+		if image.Err != nil {
+			logging.FromContext(ctx).Warn("Error while fetching image", "url", image.URL, "error", image.Err)
+			failed++
+			continue
+		}
+
+		uploadSize += int64(len(image.Data))
+
+		fileName := fmt.Sprintf("embed_%v%v", idx, image.Extension)
 
 		files = append(files, &discordgo.File{
-			Name:   fileName,
-			Reader: resp.Body,
+			Name:        fileName,
+			ContentType: image.ContentType,
+			Reader:      bytes.NewReader(image.Data),
 		})
 
-		embedMessage := fmt.Sprintf("Fetched from [nekos.best](https://nekos.best). View the original image [here](%v). Artist: [%v](%v)", res[idx].Source_url, res[idx].Artist_name, res[idx].Artist_href)
+		embedMessage := res[idx].Attribution
+		if description != "" {
+			embedMessage = description + "\n\n" + embedMessage
+		}
 
 		embeds = append(embeds, &discordgo.MessageEmbed{
-			Title:       imageType,
+			Title:       title,
 			Description: embedMessage,
 			Type:        discordgo.EmbedTypeImage,
 			Image: &discordgo.MessageEmbedImage{
@@ -98,16 +239,5 @@ func GenerateNeko(s *discordgo.Session, i *discordgo.InteractionCreate) error {
 		})
 	}
 
-	responseMessage := fmt.Sprintf("[%v](https://nekos.best) fetched successfully", imageType)
-
-	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Files:   files,
-		Embeds:  &embeds,
-		Content: &responseMessage,
-	})
-	if err != nil {
-		return fmt.Errorf("An error occurred while fetching images: %v", err)
-	}
-
-	return nil
+	return files, embeds, failed
 }
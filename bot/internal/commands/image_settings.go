@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"bot/internal/content"
+	"bot/internal/images"
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func HandleImageSettings(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database, registry *images.Registry) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	subcommand := options[0]
+	logging.ForInteraction(i).Info("Image settings command called", "subcommand", subcommand.Name)
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	var responseMessage string
+
+	switch subcommand.Name {
+	case "view":
+		contentPolicy, err := botRepository.FetchContentPolicy(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		categories := registry.Categories()
+
+		lines := make([]string, 0, len(categories))
+		for _, category := range categories {
+			lines = append(lines, fmt.Sprintf("**%v:** %v", category, content.Mode(contentPolicy, category, registry.IsSafe(category))))
+		}
+
+		imageGeneration, err := botRepository.FetchImageGeneration(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		if imageGeneration.Enabled {
+			lines = append(lines, fmt.Sprintf("**Image generation:** enabled, %v per day", imageGeneration.DailyLimit))
+		} else {
+			lines = append(lines, "**Image generation:** disabled")
+		}
+
+		responseMessage = strings.Join(lines, "\n")
+	case "set":
+		var category, mode string
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "category":
+				category = opt.StringValue()
+			case "mode":
+				mode = opt.StringValue()
+			}
+		}
+
+		err := botRepository.SetCategoryMode(i.GuildID, category, mode)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("The '%v' category is now %v.", category, mode)
+	case "generation":
+		imageGeneration, err := botRepository.FetchImageGeneration(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "enabled":
+				imageGeneration.Enabled = opt.BoolValue()
+			case "daily-limit":
+				imageGeneration.DailyLimit = int(opt.IntValue())
+			}
+		}
+
+		err = botRepository.SetImageGeneration(i.GuildID, imageGeneration)
+		if err != nil {
+			return err
+		}
+
+		if imageGeneration.Enabled {
+			responseMessage = fmt.Sprintf("Image generation is enabled with a limit of %v images per day.", imageGeneration.DailyLimit)
+		} else {
+			responseMessage = "Image generation is disabled."
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
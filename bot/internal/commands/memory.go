@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/strings"
+	"bot/internal/structs"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	gostrings "strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const memoryPageSize = 5
+
+// MemoryPagePrefix starts the custom ID of the /memory view page buttons. The
+// page number to show follows it.
+const MemoryPagePrefix = "memory:page:"
+
+func memberHasPermission(i *discordgo.InteractionCreate, permission int64) bool {
+	if i.Member == nil {
+		return false
+	}
+
+	return i.Member.Permissions&permission != 0 || i.Member.Permissions&discordgo.PermissionAdministrator != 0
+}
+
+func HandleMemory(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	subcommand := options[0]
+
+	// Forgetting your own turns is open to everyone, the rest exposes or
+	// changes the whole server's history.
+	if subcommand.Name != "forget-me" && !memberHasPermission(i, discordgo.PermissionManageGuild) {
+		return response.RespondEphemeral(s, i, "You need the Manage Server permission to use this command.")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	logging.ForInteraction(i).Info("Memory command called", "subcommand", subcommand.Name)
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	var responseMessage string
+
+	switch subcommand.Name {
+	case "view":
+		embed, components, err := MemoryPage(botRepository, i.GuildID, 0)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds:     &[]*discordgo.MessageEmbed{embed},
+			Components: &components,
+		})
+		return err
+	case "clear":
+		err := botRepository.ClearConversations(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "Conversation history cleared."
+	case "forget-me":
+		err := botRepository.RemoveUserConversations(i.GuildID, InteractionUser(i).ID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "Your messages have been removed from the conversation history."
+	case "clear-legacy":
+		err := botRepository.ClearLegacyConversations(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "Messages stored before authors were recorded have been removed from the conversation history."
+	case "export":
+		format := "json"
+		for _, opt := range subcommand.Options {
+			if opt.Name == "format" {
+				format = opt.StringValue()
+			}
+		}
+
+		return exportMemory(s, i, botRepository, format)
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
+
+// HandleMemoryPage switches a /memory view message to the page encoded in the
+// clicked button.
+func HandleMemoryPage(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	if !memberHasPermission(i, discordgo.PermissionManageGuild) {
+		return response.RespondEphemeral(s, i, "You need the Manage Server permission to use this command.")
+	}
+
+	page, err := strconv.Atoi(gostrings.TrimPrefix(i.MessageComponentData().CustomID, MemoryPagePrefix))
+	if err != nil {
+		return fmt.Errorf("invalid page: %v", err)
+	}
+
+	embed, components, err := MemoryPage(mongodb.NewBotRepository(db), i.GuildID, page)
+	if err != nil {
+		return err
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func MemoryPage(botRepository *mongodb.BotRepository, guildID string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent, error) {
+	if page < 0 {
+		page = 0
+	}
+
+	conversations, total, err := botRepository.FetchConversationsPage(guildID, page*memoryPageSize, memoryPageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pageCount := (total + memoryPageSize - 1) / memoryPageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Conversation history",
+		Footer: &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %v of %v • %v conversations stored", page+1, pageCount, total)},
+	}
+
+	if len(conversations) == 0 {
+		embed.Description = "No conversations stored in history yet."
+	}
+
+	for _, conversation := range conversations {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  strings.TruncateString(conversation.User.Name, 256),
+			Value: strings.TruncateString(fmt.Sprintf("**Message:** %v\n**Reply:** %v", conversation.User.Message, conversation.Bot), 1024),
+		})
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: MemoryPagePrefix + strconv.Itoa(page-1),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: MemoryPagePrefix + strconv.Itoa(page+1),
+					Disabled: page+1 >= pageCount,
+				},
+			},
+		},
+	}
+
+	return embed, components, nil
+}
+
+func exportMemory(s *discordgo.Session, i *discordgo.InteractionCreate, botRepository *mongodb.BotRepository, format string) error {
+	conversations, err := botRepository.FetchConversations(i.GuildID)
+	if err != nil {
+		return err
+	}
+	if conversations == nil {
+		conversations = []structs.Conversation{}
+	}
+
+	var data []byte
+	var fileName string
+
+	switch format {
+	case "markdown":
+		data = []byte(conversationsMarkdown(conversations))
+		fileName = "conversations.md"
+	default:
+		data, err = json.MarshalIndent(conversations, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to export conversations: %v", err)
+		}
+		fileName = "conversations.json"
+	}
+
+	responseMessage := fmt.Sprintf("Exported %v conversations.", len(conversations))
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+		Files: []*discordgo.File{
+			{
+				Name:   fileName,
+				Reader: bytes.NewReader(data),
+			},
+		},
+	})
+
+	return err
+}
+
+// conversationsMarkdown renders the history oldest first so it reads like a
+// chat log.
+func conversationsMarkdown(conversations []structs.Conversation) string {
+	var builder gostrings.Builder
+
+	builder.WriteString("# Conversation history\n")
+
+	for idx := len(conversations) - 1; idx >= 0; idx-- {
+		conversation := conversations[idx]
+		fmt.Fprintf(&builder, "\n**%v:** %v\n\n**Bot:** %v\n", conversation.User.Name, conversation.User.Message, conversation.Bot)
+	}
+
+	return builder.String()
+}
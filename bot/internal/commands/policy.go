@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/structs"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func HandlePolicy(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	subcommand := options[0]
+	logging.ForInteraction(i).Info("Policy command called", "subcommand", subcommand.Name)
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, opt := range subcommand.Options {
+		optionMap[opt.Name] = opt
+	}
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	var responseMessage string
+
+	switch subcommand.Name {
+	case "view":
+		policy, err := botRepository.FetchPolicy(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		modLogChannel, err := botRepository.FetchModLogChannel(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = describePolicy(policy, modLogChannel)
+	case "role":
+		roleID := optionMap["role"].RoleValue(s, i.GuildID).ID
+		access := optionMap["access"].StringValue()
+
+		err := botRepository.SetPolicyEntry(i.GuildID, "allowed_roles", roleID, access == "allow")
+		if err != nil {
+			return err
+		}
+		err = botRepository.SetPolicyEntry(i.GuildID, "denied_roles", roleID, access == "deny")
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("<@&%v> is now set to %v.", roleID, access)
+	case "channel":
+		channelID := optionMap["channel"].ChannelValue(s).ID
+		allowed := optionMap["allowed"].BoolValue()
+
+		err := botRepository.SetPolicyEntry(i.GuildID, "allowed_channels", channelID, allowed)
+		if err != nil {
+			return err
+		}
+
+		if allowed {
+			responseMessage = fmt.Sprintf("<#%v> added to the allowed channels.", channelID)
+		} else {
+			responseMessage = fmt.Sprintf("<#%v> removed from the allowed channels.", channelID)
+		}
+	case "admin-only":
+		feature := optionMap["feature"].StringValue()
+		enabled := optionMap["enabled"].BoolValue()
+
+		err := botRepository.SetPolicyEntry(i.GuildID, "admin_commands", feature, enabled)
+		if err != nil {
+			return err
+		}
+
+		if enabled {
+			responseMessage = fmt.Sprintf("`%v` is now limited to server admins.", feature)
+		} else {
+			responseMessage = fmt.Sprintf("`%v` is now open to everyone allowed by the policy.", feature)
+		}
+	case "mod-log":
+		var channelID string
+		if option, ok := optionMap["channel"]; ok {
+			channelID = option.ChannelValue(s).ID
+		}
+
+		err := botRepository.SetModLogChannel(i.GuildID, channelID)
+		if err != nil {
+			return err
+		}
+
+		if channelID != "" {
+			responseMessage = fmt.Sprintf("Moderation actions will be logged to <#%v>.", channelID)
+		} else {
+			responseMessage = "Moderation actions will no longer be logged."
+		}
+	case "reset":
+		err := botRepository.ResetPolicy(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "Policy reset. Everyone can use the bot in every channel."
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
+
+func describePolicy(policy structs.GuildPolicy, modLogChannel string) string {
+	format := func(ids []string, prefix string) string {
+		if len(ids) == 0 {
+			return "any"
+		}
+
+		mentions := make([]string, 0, len(ids))
+		for _, id := range ids {
+			mentions = append(mentions, prefix+id+">")
+		}
+
+		return strings.Join(mentions, ", ")
+	}
+
+	adminOnly := "none"
+	if len(policy.AdminCommands) > 0 {
+		adminOnly = "`" + strings.Join(policy.AdminCommands, "`, `") + "`"
+	}
+
+	deniedRoles := "none"
+	if len(policy.DeniedRoles) > 0 {
+		deniedRoles = format(policy.DeniedRoles, "<@&")
+	}
+
+	modLog := "off"
+	if modLogChannel != "" {
+		modLog = "<#" + modLogChannel + ">"
+	}
+
+	return fmt.Sprintf(
+		"**Allowed roles:** %v\n**Denied roles:** %v\n**Allowed channels:** %v\n**Admin only:** %v\n**Mod-log:** %v",
+		format(policy.AllowedRoles, "<@&"),
+		deniedRoles,
+		format(policy.AllowedChannels, "<#"),
+		adminOnly,
+		modLog,
+	)
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// InteractionUser returns the user behind an interaction, which Discord puts
+// on the member in guilds and on the interaction itself in DMs.
+func InteractionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+
+	return i.User
+}
+
+func HandlePrivacy(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	logging.ForInteraction(i).Info("Privacy command called", "subcommand", options[0].Name)
+
+	privacyRepository := mongodb.NewPrivacyRepository(db)
+	user := InteractionUser(i)
+
+	var responseMessage string
+
+	switch options[0].Name {
+	case "opt-out":
+		err := privacyRepository.SetOptedOut(user.ID, true)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "You have opted out. Your messages will no longer be stored or sent to the AI in any server. Use `/privacy delete-my-data` to also remove what was already stored."
+	case "opt-in":
+		err := privacyRepository.SetOptedOut(user.ID, false)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "You have opted back in. The bot will respond to your messages again."
+	case "delete-my-data":
+		affected, err := privacyRepository.DeleteUserData(user.ID, i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("Your stored messages have been deleted from %v server(s), along with your reminders. Your token usage is still counted towards the servers' budgets, but no longer under your name. Messages stored before the bot recorded authors can only be removed by a server admin with `/memory clear-legacy`.", affected)
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", options[0].Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
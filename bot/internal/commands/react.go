@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"bot/internal/images"
+	"bot/internal/logging"
+	"bot/internal/platform/nekosbest"
+	"bot/internal/response"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// reactionVerbs phrases the reactions that are done to someone. Reactions
+// missing here are phrased generically.
+var reactionVerbs = map[string]string{
+	"bite":      "bites",
+	"bonk":      "bonks",
+	"cuddle":    "cuddles",
+	"feed":      "feeds",
+	"handhold":  "holds hands with",
+	"handshake": "shakes hands with",
+	"highfive":  "high-fives",
+	"hug":       "hugs",
+	"kick":      "kicks",
+	"kiss":      "kisses",
+	"nibble":    "nibbles on",
+	"pat":       "pats",
+	"peck":      "pecks",
+	"poke":      "pokes",
+	"punch":     "punches",
+	"shoot":     "shoots",
+	"slap":      "slaps",
+	"stare":     "stares at",
+	"tickle":    "tickles",
+	"wave":      "waves at",
+	"wink":      "winks at",
+	"yeet":      "yeets",
+}
+
+func HandleReact(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database, registry *images.Registry) error {
+	var action string
+	var target *discordgo.User
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "action":
+			action = strings.ToLower(opt.StringValue())
+		case "user":
+			target = opt.UserValue(s)
+		}
+	}
+
+	if !nekosbest.IsGifCategory(action) {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("'%v' is not a valid reaction.", action))
+	}
+
+	// Reactions always come from nekos.best, which only serves safe images.
+	refused, err := checkContentPolicy(s, i, db, action, true)
+	if refused || err != nil {
+		return err
+	}
+
+	err = response.DeferResponse(s, i, "Please wait while we fetch the reaction...")
+	if err != nil {
+		return err
+	}
+
+	// Reactions are a nekos.best feature, so they bypass the provider registry
+	// but still share its loader and cache.
+	ctx := logging.InteractionContext(i)
+
+	res, err := nekosbest.NewProvider().Fetch(ctx, action, 1)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error while fetching reaction", "error", err)
+		return fmt.Errorf("Failed to fetch %v: %v", action, err)
+	}
+
+	author := InteractionUser(i)
+	description := reactionSentence(action, author, target)
+
+	files, embeds, _ := renderImages(ctx, registry.Loader, action, description, res)
+
+	if len(files) == 0 {
+		return fmt.Errorf("The reaction could not be fetched, please try again.")
+	}
+
+	responseMessage := ""
+	allowedMentions := &discordgo.MessageAllowedMentions{
+		Parse: []discordgo.AllowedMentionType{},
+	}
+
+	if target != nil {
+		responseMessage = "<@" + target.ID + ">"
+		allowedMentions.Users = []string{target.ID}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Files:           files,
+		Embeds:          &embeds,
+		Content:         &responseMessage,
+		AllowedMentions: allowedMentions,
+	})
+	if err != nil {
+		return fmt.Errorf("An error occurred while fetching the reaction: %v", err)
+	}
+
+	return nil
+}
+
+func reactionSentence(action string, author *discordgo.User, target *discordgo.User) string {
+	if target == nil || target.ID == author.ID {
+		return fmt.Sprintf("<@%v> is feeling **%v**", author.ID, action)
+	}
+
+	if verb, ok := reactionVerbs[action]; ok {
+		return fmt.Sprintf("<@%v> %v <@%v>", author.ID, verb, target.ID)
+	}
+
+	return fmt.Sprintf("<@%v> reacts to <@%v> with **%v**", author.ID, target.ID, action)
+}
+
+// AutocompleteReact filters the reaction list by what has been typed, since
+// there are more reactions than Discord allows as fixed choices.
+func AutocompleteReact(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var typed string
+
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "action" {
+			typed = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+
+	for _, category := range nekosbest.GifCategories {
+		if len(choices) == 25 {
+			break
+		}
+		if strings.Contains(category, typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  category,
+				Value: category,
+			})
+		}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/platform/gemini/tools"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/strings"
+	"bot/internal/structs"
+	"fmt"
+	gostrings "strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func HandleRemind(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	logging.ForInteraction(i).Info("Remind command called", "subcommand", options[0].Name)
+
+	reminderRepository := mongodb.NewReminderRepository(db)
+	user := InteractionUser(i)
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range options[0].Options {
+		optionMap[opt.Name] = opt
+	}
+
+	var responseMessage string
+
+	switch options[0].Name {
+	case "create":
+		when := optionMap["when"].StringValue()
+		message := gostrings.TrimSpace(optionMap["message"].StringValue())
+
+		var timezone string
+		if opt, ok := optionMap["timezone"]; ok {
+			timezone = opt.StringValue()
+		}
+
+		// A delay such as '2 hours' is tried first, anything else is read as a
+		// time in the given zone.
+		var in, at string
+		if _, err := tools.ParseDelay(when); err == nil {
+			in = when
+		} else {
+			at = when
+		}
+
+		dueAt, err := tools.ReminderTime(in, at, timezone)
+		if err != nil {
+			return err
+		}
+
+		reminder, err := reminderRepository.Create(structs.Reminder{
+			GuildID:   i.GuildID,
+			ChannelID: i.ChannelID,
+			UserID:    user.ID,
+			Message:   message,
+			DueAt:     dueAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("I will remind you <t:%v:R> (<t:%v:F>): %v", reminder.DueAt.Unix(), reminder.DueAt.Unix(), reminder.Message)
+	case "list":
+		reminders, err := reminderRepository.ListPending(i.GuildID, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(reminders) == 0 {
+			responseMessage = "You have no pending reminders in this server."
+			break
+		}
+
+		var builder gostrings.Builder
+		builder.WriteString("Your pending reminders:\n")
+		for _, reminder := range reminders {
+			builder.WriteString(fmt.Sprintf("- <t:%v:F> in <#%v>: %v (`%v`)\n", reminder.DueAt.Unix(), reminder.ChannelID, reminder.Message, reminder.ID.Hex()))
+		}
+
+		responseMessage = strings.TruncateString(builder.String(), 2000)
+	case "cancel":
+		cancelled, err := reminderRepository.Cancel(optionMap["id"].StringValue(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		if cancelled {
+			responseMessage = "The reminder has been cancelled."
+		} else {
+			responseMessage = "No pending reminder of yours has that ID. Use `/remind list` to see your reminders."
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", options[0].Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
+
+// AutocompleteReminder suggests the user's pending reminders for the cancel
+// subcommand.
+func AutocompleteReminder(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	var query string
+
+	for _, subcommand := range i.ApplicationCommandData().Options {
+		for _, opt := range subcommand.Options {
+			if opt.Name == "id" {
+				query = gostrings.ToLower(gostrings.TrimSpace(opt.StringValue()))
+			}
+		}
+	}
+
+	reminders, err := mongodb.NewReminderRepository(db).ListPending(i.GuildID, InteractionUser(i).ID)
+	if err != nil {
+		logging.ForInteraction(i).Error("Error while listing reminders for autocomplete", "error", err)
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+
+	for _, reminder := range reminders {
+		if len(choices) == 25 {
+			break
+		}
+
+		name := fmt.Sprintf("%v UTC: %v", reminder.DueAt.Format("2006-01-02 15:04"), reminder.Message)
+		if query != "" && !gostrings.Contains(gostrings.ToLower(name), query) {
+			continue
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateChoice(name),
+			Value: reminder.ID.Hex(),
+		})
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
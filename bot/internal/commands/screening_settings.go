@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/screening"
+	"bot/internal/storage/mongodb"
+	"bot/internal/strings"
+	"bot/internal/structs"
+	"fmt"
+	"slices"
+	gostrings "strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	maxBlockedWords    = 100
+	maxBlockedPatterns = 20
+)
+
+func HandleScreeningSettings(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	subcommand := options[0]
+	logging.ForInteraction(i).Info("Screening settings command called", "subcommand", subcommand.Name)
+
+	optionMap := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(subcommand.Options))
+	for _, opt := range subcommand.Options {
+		optionMap[opt.Name] = opt
+	}
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	inputScreening, err := botRepository.FetchInputScreening(i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	outputFilter, err := botRepository.FetchOutputFilter(i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	var responseMessage string
+
+	switch subcommand.Name {
+	case "view":
+		responseMessage = strings.TruncateString(describeScreening(inputScreening, outputFilter), 2000)
+	case "injection-filter":
+		inputScreening.InjectionFilter = optionMap["enabled"].BoolValue()
+
+		responseMessage = fmt.Sprintf("The prompt-injection filter is now %v.", enabledText(inputScreening.InjectionFilter))
+	case "blocked-word":
+		word := screening.Normalize(optionMap["word"].StringValue())
+		if word == "" {
+			return fmt.Errorf("the word cannot be empty")
+		}
+
+		if optionMap["blocked"].BoolValue() {
+			if !slices.Contains(inputScreening.BlockedWords, word) {
+				if len(inputScreening.BlockedWords) >= maxBlockedWords {
+					return fmt.Errorf("at most %v words can be blocked", maxBlockedWords)
+				}
+				inputScreening.BlockedWords = append(inputScreening.BlockedWords, word)
+			}
+
+			responseMessage = fmt.Sprintf("Messages containing `%v` are now blocked, and it is filtered from replies.", word)
+		} else {
+			inputScreening.BlockedWords = slices.DeleteFunc(inputScreening.BlockedWords, func(blocked string) bool {
+				return blocked == word
+			})
+
+			responseMessage = fmt.Sprintf("`%v` is no longer blocked.", word)
+		}
+	case "blocked-pattern":
+		pattern := optionMap["pattern"].StringValue()
+
+		if optionMap["blocked"].BoolValue() {
+			if _, err := screening.CompilePattern(pattern); err != nil {
+				return err
+			}
+
+			if !slices.Contains(inputScreening.BlockedPatterns, pattern) {
+				if len(inputScreening.BlockedPatterns) >= maxBlockedPatterns {
+					return fmt.Errorf("at most %v patterns can be blocked", maxBlockedPatterns)
+				}
+				inputScreening.BlockedPatterns = append(inputScreening.BlockedPatterns, pattern)
+			}
+
+			responseMessage = fmt.Sprintf("Messages matching `%v` are now blocked.", pattern)
+		} else {
+			inputScreening.BlockedPatterns = slices.DeleteFunc(inputScreening.BlockedPatterns, func(blocked string) bool {
+				return blocked == pattern
+			})
+
+			responseMessage = fmt.Sprintf("`%v` is no longer blocked.", pattern)
+		}
+	case "safety":
+		level := optionMap["level"].StringValue()
+		if level == "off" {
+			level = ""
+		} else if !screening.ValidSafetyThreshold(level) {
+			return fmt.Errorf("unknown safety level '%v'", level)
+		}
+		inputScreening.SafetyThreshold = level
+
+		if level == "" {
+			responseMessage = "Messages are no longer classified with Gemini's safety filters."
+		} else {
+			responseMessage = fmt.Sprintf("Messages are now classified with Gemini's safety filters, blocking %v risk and above. Each check is a request on this server's API key.", level)
+		}
+	case "reply":
+		var message string
+		if option, ok := optionMap["message"]; ok {
+			message = gostrings.TrimSpace(option.StringValue())
+		}
+		inputScreening.BlockedReply = message
+
+		if message == "" {
+			responseMessage = fmt.Sprintf("Blocked messages now get the default reply: %v", structs.DefaultBlockedReply)
+		} else {
+			responseMessage = fmt.Sprintf("Blocked messages now get the reply: %v", message)
+		}
+	case "output":
+		if option, ok := optionMap["filter-invites"]; ok {
+			outputFilter.AllowInvites = !option.BoolValue()
+		}
+		if option, ok := optionMap["filter-mass-mentions"]; ok {
+			outputFilter.AllowMassMentions = !option.BoolValue()
+		}
+		if option, ok := optionMap["mode"]; ok {
+			outputFilter.Mode = option.StringValue()
+		}
+
+		err := botRepository.SetOutputFilter(i.GuildID, outputFilter)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = "Output filter updated.\n" + describeOutputFilter(outputFilter)
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	if subcommand.Name != "view" && subcommand.Name != "output" {
+		err = botRepository.SetInputScreening(i.GuildID, inputScreening)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
+
+func describeScreening(inputScreening structs.InputScreening, outputFilter structs.OutputFilter) string {
+	format := func(values []string) string {
+		if len(values) == 0 {
+			return "none"
+		}
+
+		return "`" + gostrings.Join(values, "`, `") + "`"
+	}
+
+	safety := "off"
+	if inputScreening.SafetyThreshold != "" {
+		safety = inputScreening.SafetyThreshold + " risk and above"
+	}
+
+	blockedReply := inputScreening.BlockedReply
+	if blockedReply == "" {
+		blockedReply = structs.DefaultBlockedReply + " (default)"
+	}
+
+	return fmt.Sprintf(
+		"**Prompt-injection filter:** %v\n**Blocked words:** %v\n**Blocked patterns:** %v\n**Safety classification:** %v\n**Reply to blocked messages:** %v\n%v",
+		enabledText(inputScreening.InjectionFilter),
+		format(inputScreening.BlockedWords),
+		format(inputScreening.BlockedPatterns),
+		safety,
+		blockedReply,
+		describeOutputFilter(outputFilter),
+	)
+}
+
+func describeOutputFilter(outputFilter structs.OutputFilter) string {
+	mode := "neutralized"
+	if outputFilter.Mode == screening.OutputStrip {
+		mode = "removed"
+	}
+
+	return fmt.Sprintf(
+		"**Invites in replies:** %v\n**Mass mentions in replies:** %v\n**Filtered content is:** %v",
+		filteredText(!outputFilter.AllowInvites),
+		filteredText(!outputFilter.AllowMassMentions),
+		mode,
+	)
+}
+
+func filteredText(filtered bool) string {
+	if filtered {
+		return "filtered"
+	}
+
+	return "allowed"
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/search"
+	"bot/internal/storage/mongodb"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// searchProviderNotes tells admins what each provider needs before choosing
+// it.
+var searchProviderNotes = map[string]string{
+	search.VyntrProvider:   "uses the Vyntr API key from the dashboard",
+	search.SearXNGProvider: "uses the SearXNG instance of the bot's host, no key needed",
+	search.BraveProvider:   "uses the Brave Search API key from the dashboard",
+	search.GoogleProvider:  "uses Google Search grounding with the Google AI API key",
+}
+
+func HandleSearchSettings(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	subcommand := options[0]
+	logging.ForInteraction(i).Info("Search settings command called", "subcommand", subcommand.Name)
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	var responseMessage string
+
+	switch subcommand.Name {
+	case "view":
+		provider, err := botRepository.FetchSearchProvider(i.GuildID)
+		if err != nil {
+			return err
+		}
+		if provider == "" {
+			provider = search.DefaultProvider
+		}
+
+		prefetchLinks, err := botRepository.FetchPrefetchLinks(i.GuildID)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("**Search provider:** %v (%v)\n**Read links in messages:** %v", provider, searchProviderNotes[provider], enabledText(prefetchLinks))
+	case "provider":
+		provider := subcommand.Options[0].StringValue()
+		if _, ok := searchProviderNotes[provider]; !ok {
+			return fmt.Errorf("unknown search provider '%v'", provider)
+		}
+
+		err := botRepository.SetSearchProvider(i.GuildID, provider)
+		if err != nil {
+			return err
+		}
+
+		responseMessage = fmt.Sprintf("Web searches now use %v, which %v.", provider, searchProviderNotes[provider])
+	case "prefetch-links":
+		enabled := subcommand.Options[0].BoolValue()
+
+		err := botRepository.SetPrefetchLinks(i.GuildID, enabled)
+		if err != nil {
+			return err
+		}
+
+		if enabled {
+			responseMessage = "Links in messages to the bot are now read before it answers."
+		} else {
+			responseMessage = "Links in messages are no longer read up front. The AI can still open them when asked."
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &responseMessage,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+	}
+
+	return nil
+}
+
+func enabledText(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+
+	return "disabled"
+}
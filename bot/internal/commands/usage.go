@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"bot/internal/logging"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/structs"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// usageTopUsers is how many users the breakdown lists.
+const usageTopUsers = 10
+
+func HandleUsage(s *discordgo.Session, i *discordgo.InteractionCreate, db *mongo.Database) error {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return fmt.Errorf("no subcommand given")
+	}
+
+	err := response.DeferEphemeralResponse(s, i)
+	if err != nil {
+		return err
+	}
+
+	subcommand := options[0]
+	logging.ForInteraction(i).Info("Usage command called", "subcommand", subcommand.Name)
+
+	botRepository := mongodb.NewBotRepository(db)
+
+	budget, err := botRepository.FetchUsageBudget(i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	switch subcommand.Name {
+	case "view":
+		embed, err := usageEmbed(mongodb.NewUsageRepository(db), i.GuildID, budget)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Embeds: &[]*discordgo.MessageEmbed{embed},
+		})
+		if err != nil {
+			logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+		}
+	case "budget":
+		for _, opt := range subcommand.Options {
+			switch opt.Name {
+			case "daily-tokens":
+				budget.DailyTokens = opt.IntValue()
+			case "monthly-tokens":
+				budget.MonthlyTokens = opt.IntValue()
+			}
+		}
+
+		err := botRepository.SetUsageBudget(i.GuildID, budget)
+		if err != nil {
+			return err
+		}
+
+		responseMessage := fmt.Sprintf("**Daily budget:** %v\n**Monthly budget:** %v", budgetText(budget.DailyTokens), budgetText(budget.MonthlyTokens))
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content: &responseMessage,
+		})
+		if err != nil {
+			logging.ForInteraction(i).Error("Failed to respond to interaction", "error", err)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%v'", subcommand.Name)
+	}
+
+	return nil
+}
+
+func usageEmbed(usageRepository *mongodb.UsageRepository, guildID string, budget structs.UsageBudget) (*discordgo.MessageEmbed, error) {
+	today, month, err := usageRepository.Totals(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	topUsers, err := usageRepository.TopUsers(guildID, usageTopUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "AI usage",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Today", Value: usagePeriodText(today, budget.DailyTokens), Inline: true},
+			{Name: "This month", Value: usagePeriodText(month, budget.MonthlyTokens), Inline: true},
+			{Name: "Breakdown this month", Value: fmt.Sprintf(
+				"Prompt: %v\nReplies: %v\nTool use: %v\nThinking: %v",
+				formatTokens(month.Prompt),
+				formatTokens(month.Candidates),
+				formatTokens(month.Tool),
+				formatTokens(month.Thoughts),
+			)},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "Days and months are counted in UTC. Usage is recorded on this server's Google AI key."},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	if len(topUsers) > 0 {
+		lines := make([]string, 0, len(topUsers))
+		for idx, user := range topUsers {
+			lines = append(lines, fmt.Sprintf("%v. <@%v>: %v tokens in %v request(s)", idx+1, user.UserID, formatTokens(user.Usage.Total), user.Usage.Requests))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Top users this month", Value: strings.Join(lines, "\n")})
+	}
+
+	return embed, nil
+}
+
+func usagePeriodText(usage structs.TokenUsage, budget int64) string {
+	text := fmt.Sprintf("%v tokens\n%v request(s)", formatTokens(usage.Total), usage.Requests)
+	if budget > 0 {
+		text += fmt.Sprintf("\n%v%% of %v", usage.Total*100/budget, formatTokens(budget))
+	}
+
+	return text
+}
+
+func budgetText(tokens int64) string {
+	if tokens <= 0 {
+		return "no limit"
+	}
+
+	return formatTokens(tokens) + " tokens"
+}
+
+// formatTokens groups the digits of a token count by thousands.
+func formatTokens(tokens int64) string {
+	digits := fmt.Sprint(tokens)
+
+	var grouped strings.Builder
+	for idx, digit := range digits {
+		if idx > 0 && (len(digits)-idx)%3 == 0 {
+			grouped.WriteRune(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return grouped.String()
+}
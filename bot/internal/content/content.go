@@ -0,0 +1,85 @@
+package content
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	ModeEnabled  = "enabled"
+	ModeNSFWOnly = "nsfw-only"
+	ModeDisabled = "disabled"
+)
+
+// Mode returns how a guild treats an image category. Until the admins choose,
+// categories not known to be safe are only allowed in age-restricted channels.
+func Mode(policy structs.ContentPolicy, category string, safe bool) string {
+	switch {
+	case slices.Contains(policy.DisabledCategories, category):
+		return ModeDisabled
+	case slices.Contains(policy.NSFWOnlyCategories, category):
+		return ModeNSFWOnly
+	case slices.Contains(policy.EnabledCategories, category), safe:
+		return ModeEnabled
+	default:
+		return ModeNSFWOnly
+	}
+}
+
+// IsNSFWChannel reports whether a channel is age-restricted. Threads take the
+// setting of their parent channel.
+func IsNSFWChannel(s *discordgo.Session, channelID string) (bool, error) {
+	channel, err := fetchChannel(s, channelID)
+	if err != nil {
+		return false, err
+	}
+
+	if channel.IsThread() && channel.ParentID != "" {
+		parent, err := fetchChannel(s, channel.ParentID)
+		if err != nil {
+			return false, err
+		}
+		return parent.NSFW, nil
+	}
+
+	return channel.NSFW, nil
+}
+
+func fetchChannel(s *discordgo.Session, channelID string) (*discordgo.Channel, error) {
+	channel, err := s.State.Channel(channelID)
+	if err == nil {
+		return channel, nil
+	}
+
+	channel, err = s.Channel(channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch channel: %w", err)
+	}
+
+	return channel, nil
+}
+
+// CheckImageCategory decides whether images of a category may be posted in a
+// channel. The returned error explains the refusal and can be shown as is.
+func CheckImageCategory(s *discordgo.Session, policy structs.ContentPolicy, category string, safe bool, channelID string) error {
+	switch Mode(policy, category, safe) {
+	case ModeDisabled:
+		return fmt.Errorf("The '%v' category has been disabled by the server admins.", category)
+	case ModeNSFWOnly:
+		nsfw, err := IsNSFWChannel(s, channelID)
+		if err != nil {
+			slog.Error("Error while checking channel NSFW status", "error", err)
+			return fmt.Errorf("Could not check whether this channel is age-restricted, so the '%v' category cannot be posted here.", category)
+		}
+		if !nsfw {
+			return fmt.Errorf("The '%v' category can only be posted in age-restricted channels. Server admins can change this with /image-settings.", category)
+		}
+	}
+
+	return nil
+}
@@ -7,15 +7,20 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 
 	"bot/internal/commands"
+	"bot/internal/images"
+	"bot/internal/logging"
+	"bot/internal/response"
 )
 
 type CommandParams struct {
-	Db *mongo.Database
+	Db     *mongo.Database
+	Images *images.Registry
 }
 
-func CommandHandler(db *mongo.Database) *CommandParams {
+func CommandHandler(db *mongo.Database, registry *images.Registry) *CommandParams {
 	return &CommandParams{
-		Db: db,
+		Db:     db,
+		Images: registry,
 	}
 }
 
@@ -24,26 +29,160 @@ func (r *CommandParams) HandleCommand(s *discordgo.Session, i *discordgo.Interac
 		return
 	}
 
-	switch i.ApplicationCommandData().Name {
+	commandName := i.ApplicationCommandData().Name
+
+	ctx := logging.InteractionContext(i)
+	logger := logging.FromContext(ctx).With("command", commandName)
+
+	logger.Debug("Command received")
+
+	// Privacy controls stay reachable for everyone, and the settings commands
+	// are already limited to admins by their default permissions.
+	if commandName != "privacy" && commandName != "policy" && commandName != "image-settings" && commandName != "search-settings" && commandName != "screening-settings" && commandName != "usage" {
+		err := checkPolicy(ctx, s, r.Db, i.GuildID, i.ChannelID, commands.InteractionUser(i).ID, i.Member, commandName)
+		if err != nil {
+			response.RespondEphemeral(s, i, err.Error())
+			return
+		}
+	}
+
+	switch commandName {
 	case "load-name":
 		err := commands.UpdateBotNickname(s, i.GuildID, i, r.Db)
 
 		if err != nil {
-			fmt.Println("Error while updating bot nickname:", err)
+			logger.Error("Error while updating bot nickname", "error", err)
 			errorMessage := fmt.Sprintf("Error while updating bot nickname: %v", err)
 			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 				Content: &errorMessage,
 			})
 		}
 	case "fetch-neko":
-		err := commands.GenerateNeko(s, i)
+		err := commands.GenerateNeko(s, i, r.Db, r.Images)
 
 		if err != nil {
-			fmt.Println("Error while fetching neko:", err)
+			logger.Error("Error while fetching neko", "error", err)
 			errorMessage := fmt.Sprintf("Error while fetching husbando/kitsune/neko/waifu: %v", err)
 			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
 				Content: &errorMessage,
 			})
 		}
+	case "react":
+		err := commands.HandleReact(s, i, r.Db, r.Images)
+
+		if err != nil {
+			logger.Error("Error while handling react command", "error", err)
+			errorMessage := fmt.Sprintf("Error while fetching reaction: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "memory":
+		err := commands.HandleMemory(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling memory command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling memory command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "policy":
+		err := commands.HandlePolicy(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling policy command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling policy command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "image-settings":
+		err := commands.HandleImageSettings(s, i, r.Db, r.Images)
+
+		if err != nil {
+			logger.Error("Error while handling image settings command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling image settings command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "search-settings":
+		err := commands.HandleSearchSettings(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling search settings command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling search settings command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "screening-settings":
+		err := commands.HandleScreeningSettings(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling screening settings command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling screening settings command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "usage":
+		err := commands.HandleUsage(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling usage command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling usage command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "calc":
+		err := commands.HandleCalc(s, i)
+
+		if err != nil {
+			logger.Error("Error while handling calc command", "error", err)
+		}
+	case "remind":
+		err := commands.HandleRemind(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling remind command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling remind command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	case "privacy":
+		err := commands.HandlePrivacy(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while handling privacy command", "error", err)
+			errorMessage := fmt.Sprintf("Error while handling privacy command: %v", err)
+			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &errorMessage,
+			})
+		}
+	}
+}
+
+func (r *CommandParams) HandleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionApplicationCommandAutocomplete {
+		return
+	}
+
+	var err error
+
+	switch i.ApplicationCommandData().Name {
+	case "fetch-neko":
+		err = commands.AutocompleteNekoSearch(s, i, r.Images)
+	case "react":
+		err = commands.AutocompleteReact(s, i)
+	case "remind":
+		err = commands.AutocompleteReminder(s, i, r.Db)
+	}
+
+	if err != nil {
+		logging.ForInteraction(i).Error("Error while responding to autocomplete", "error", err)
 	}
 }
@@ -0,0 +1,305 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	gostrings "strings"
+	"sync"
+
+	"bot/internal/commands"
+	"bot/internal/logging"
+	"bot/internal/moderation"
+	"bot/internal/platform/gemini"
+	"bot/internal/policy"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/strings"
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	replyRegenerateID = "reply:regenerate"
+	replyContinueID   = "reply:continue"
+	replyDeleteID     = "reply:delete"
+)
+
+// replyLocks holds the IDs of AI replies that are currently being acted on, so
+// that simultaneous clicks on the same reply cannot interleave their updates.
+var replyLocks sync.Map
+
+type ComponentParams struct {
+	Db *mongo.Database
+}
+
+func ComponentHandler(db *mongo.Database) *ComponentParams {
+	return &ComponentParams{
+		Db: db,
+	}
+}
+
+func replyComponents() []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Regenerate",
+					Style:    discordgo.SecondaryButton,
+					CustomID: replyRegenerateID,
+				},
+				discordgo.Button{
+					Label:    "Continue",
+					Style:    discordgo.SecondaryButton,
+					CustomID: replyContinueID,
+				},
+				discordgo.Button{
+					Label:    "Delete",
+					Style:    discordgo.DangerButton,
+					CustomID: replyDeleteID,
+				},
+			},
+		},
+	}
+}
+
+func (r *ComponentParams) HandleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	customID := i.MessageComponentData().CustomID
+
+	ctx := logging.InteractionContext(i)
+	logger := logging.FromContext(ctx).With("custom_id", customID)
+
+	switch customID {
+	case replyRegenerateID, replyContinueID, replyDeleteID:
+		err := r.handleReplyAction(ctx, s, i, customID)
+
+		if err != nil {
+			logger.Error("Error while handling reply action", "error", err)
+			response.FollowUpEphemeral(s, i, fmt.Sprintf("Error while updating the reply: %v", err))
+		}
+		return
+	}
+
+	switch {
+	case gostrings.HasPrefix(customID, commands.MemoryPagePrefix):
+		err := commands.HandleMemoryPage(s, i, r.Db)
+
+		if err != nil {
+			logger.Error("Error while changing memory page", "error", err)
+			response.RespondEphemeral(s, i, fmt.Sprintf("Error while changing page: %v", err))
+		}
+	case gostrings.HasPrefix(customID, moderationConfirmPrefix), gostrings.HasPrefix(customID, moderationCancelPrefix):
+		err := r.handleModerationAction(ctx, s, i, customID)
+
+		if err != nil {
+			logger.Error("Error while handling moderation action", "error", err)
+			response.FollowUpEphemeral(s, i, fmt.Sprintf("Error while handling the action: %v", err))
+		}
+	}
+}
+
+func (r *ComponentParams) handleReplyAction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	logger := logging.FromContext(ctx)
+
+	if _, busy := replyLocks.LoadOrStore(i.Message.ID, struct{}{}); busy {
+		return response.RespondEphemeral(s, i, "This reply is already being updated, please wait a moment.")
+	}
+	defer replyLocks.Delete(i.Message.ID)
+
+	botRepository := mongodb.NewBotRepository(r.Db)
+
+	conversation, err := botRepository.FetchConversation(i.GuildID, i.Message.ID)
+	if err != nil {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Error while fetching the conversation: %v", err))
+	}
+	if conversation == nil {
+		return response.RespondEphemeral(s, i, "This reply is no longer stored in the conversation history.")
+	}
+
+	// The reply is regenerated or continued as the original request, with the
+	// requester's budget and permissions, so only they or a moderator may.
+	if !canManageReply(i, conversation) {
+		return response.RespondEphemeral(s, i, "Only the person who asked or a moderator can change this reply.")
+	}
+
+	if customID == replyDeleteID {
+		return r.deleteReply(ctx, s, i, botRepository, conversation)
+	}
+
+	err = checkPolicy(ctx, s, r.Db, i.GuildID, i.ChannelID, commands.InteractionUser(i).ID, i.Member, policy.MentionFeature)
+	if err != nil {
+		return response.RespondEphemeral(s, i, err.Error())
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to defer update: %v", err)
+	}
+
+	privacyRepository := mongodb.NewPrivacyRepository(r.Db)
+
+	optedOut, err := privacyRepository.IsOptedOut(conversation.User.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check privacy setting: %v", err)
+	}
+	if optedOut {
+		return response.FollowUpEphemeral(s, i, "The person who asked has opted out, so this reply cannot be regenerated or continued.")
+	}
+
+	geminiAPIClient := gemini.NewAPIRequest(ctx, s, r.Db, originalMessage(ctx, s, i, conversation))
+	geminiAPIClient.ExcludeConversation = conversation.ID
+
+	// The screening settings may have changed since the reply was written.
+	inputScreening, err := botRepository.FetchInputScreening(i.GuildID)
+	if err != nil {
+		logger.Error("Failed to fetch screening settings", "error", err)
+	}
+
+	verdict := geminiAPIClient.ScreenInput(inputScreening)
+	if verdict.Blocked {
+		logger.Info("Returning as message was blocked", "stage", verdict.Stage, logging.Content("reason", verdict.Reason))
+
+		blockedReply := inputScreening.BlockedReply
+		if blockedReply == "" {
+			blockedReply = structs.DefaultBlockedReply
+		}
+
+		modLogChannel, err := botRepository.FetchModLogChannel(i.GuildID)
+		if err != nil {
+			logger.Error("Failed to fetch mod-log channel", "error", err)
+		}
+		moderation.LogBlockedMessage(s, modLogChannel, geminiAPIClient.M.Message, verdict.Stage, verdict.Reason)
+
+		return response.FollowUpEphemeral(s, i, blockedReply)
+	}
+
+	switch customID {
+	case replyRegenerateID:
+		logger.Info("Regenerating reply", "reply_id", conversation.ID)
+
+		reply := geminiAPIClient.RequestGenAi()
+		if reply.Text == "" {
+			return response.FollowUpEphemeral(s, i, reply.Content)
+		}
+
+		content := strings.TruncateString(reply.Content, 2000)
+		_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:      i.Message.ID,
+			Channel: i.ChannelID,
+			Content: &content,
+			// Replace images generated for the old answer with the new ones.
+			Files:           reply.Files,
+			Attachments:     &[]*discordgo.MessageAttachment{},
+			AllowedMentions: requesterMentions(conversation.User.ID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to edit reply: %v", err)
+		}
+
+		sendActionConfirmations(ctx, s, i.Message, reply.Actions)
+
+		for _, followUp := range conversation.FollowUps {
+			if err := s.ChannelMessageDelete(i.ChannelID, followUp); err != nil {
+				logger.Error("Failed to delete follow-up message", "error", err)
+			}
+		}
+
+		return botRepository.ReplaceConversationReply(i.GuildID, conversation.ID, reply.Text)
+	case replyContinueID:
+		logger.Info("Continuing reply", "reply_id", conversation.ID)
+
+		reply := geminiAPIClient.RequestContinuation(conversation.Bot)
+		if reply.Text == "" {
+			return response.FollowUpEphemeral(s, i, reply.Content)
+		}
+
+		sent, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{
+			Content:         strings.TruncateString(reply.Content, 2000),
+			Reference:       i.Message.Reference(),
+			Files:           reply.Files,
+			AllowedMentions: requesterMentions(conversation.User.ID),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send continuation: %v", err)
+		}
+
+		sendActionConfirmations(ctx, s, sent, reply.Actions)
+
+		return botRepository.ExtendConversationReply(i.GuildID, conversation.ID, conversation.Bot+"\n"+reply.Text, sent.ID)
+	}
+
+	return nil
+}
+
+// canManageReply reports whether the member who clicked asked for the reply or
+// may manage messages in the channel.
+func canManageReply(i *discordgo.InteractionCreate, conversation *structs.Conversation) bool {
+	user := commands.InteractionUser(i)
+	isRequester := user != nil && user.ID == conversation.User.ID
+	isModerator := i.Member != nil && i.Member.Permissions&discordgo.PermissionManageMessages != 0
+
+	return isRequester || isModerator
+}
+
+func (r *ComponentParams) deleteReply(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, botRepository *mongodb.BotRepository, conversation *structs.Conversation) error {
+	logger := logging.FromContext(ctx)
+
+	logger.Info("Deleting reply", "reply_id", conversation.ID)
+
+	err := botRepository.RemoveConversation(i.GuildID, conversation.ID)
+	if err != nil {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Error while deleting the reply: %v", err))
+	}
+
+	for _, messageID := range append([]string{conversation.ID}, conversation.FollowUps...) {
+		if err := s.ChannelMessageDelete(i.ChannelID, messageID); err != nil {
+			logger.Error("Failed to delete reply message", "error", err)
+		}
+	}
+
+	return response.RespondEphemeral(s, i, "Reply deleted.")
+}
+
+// originalMessage fetches the message a stored conversation answered. If it
+// was deleted in the meantime, the message is rebuilt from what was stored.
+func originalMessage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, conversation *structs.Conversation) *discordgo.MessageCreate {
+	logger := logging.FromContext(ctx)
+
+	var message *discordgo.Message
+
+	if conversation.RequestID != "" {
+		fetched, err := s.ChannelMessage(conversation.ChannelID, conversation.RequestID)
+		if err != nil {
+			logger.Error("Failed to fetch original message", "error", err)
+		} else {
+			message = fetched
+		}
+	}
+
+	if message == nil {
+		logger.Debug("Rebuilding original message from history")
+
+		message = &discordgo.Message{
+			ID:        conversation.RequestID,
+			ChannelID: conversation.ChannelID,
+			Content:   conversation.User.Message,
+			Author: &discordgo.User{
+				ID:         conversation.User.ID,
+				Username:   conversation.User.Name,
+				GlobalName: conversation.User.Name,
+			},
+		}
+	}
+
+	// Messages fetched over REST do not carry the guild ID.
+	message.GuildID = i.GuildID
+
+	return &discordgo.MessageCreate{Message: message}
+}
@@ -1,11 +1,14 @@
 package discord
 
 import (
-	"fmt"
-
+	"bot/internal/logging"
+	"bot/internal/metrics"
+	"bot/internal/moderation"
 	"bot/internal/platform/gemini"
+	"bot/internal/policy"
 	"bot/internal/storage/mongodb"
 	"bot/internal/strings"
+	"bot/internal/structs"
 
 	"github.com/bwmarrin/discordgo"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -22,17 +25,23 @@ func MessageHandler(db *mongo.Database) *MessageParams {
 }
 
 func (r *MessageParams) HandleMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
-	fmt.Println("Message created, running checks.")
+	ctx := logging.MessageContext(m.Message)
+	logger := logging.FromContext(ctx)
 
-	botRepository := mongodb.NewBotRepository(r.Db)
-	geminiAPIClient := gemini.NewAPIRequest(botRepository, m)
+	logger.Debug("Message created, running checks")
 
 	// Ignore messages created by the bot
 	if m.Author.ID == s.State.User.ID {
-		fmt.Println("Returning as message is created by bot itself.")
+		logger.Debug("Returning as message is created by bot itself")
 		return
 	}
 
+	metrics.MessagesHandled.Inc()
+
+	botRepository := mongodb.NewBotRepository(r.Db)
+	privacyRepository := mongodb.NewPrivacyRepository(r.Db)
+	geminiAPIClient := gemini.NewAPIRequest(ctx, s, r.Db, m)
+
 	mentioned := false
 
 	for _, user := range m.Mentions {
@@ -43,18 +52,112 @@ func (r *MessageParams) HandleMessageCreate(s *discordgo.Session, m *discordgo.M
 	}
 
 	if mentioned {
-		err := s.ChannelTyping(m.ChannelID)
+		optedOut, err := privacyRepository.IsOptedOut(m.Author.ID)
 		if err != nil {
-			fmt.Println("Failed to add typing indicator:", err)
-			s.ChannelMessageSend(m.ChannelID, strings.TruncateString("Failed to respond.", 2000))
+			logger.Error("Failed to check privacy setting", "error", err)
+			return
+		}
+		if optedOut {
+			logger.Info("Returning as user has opted out")
+			sendToRequester(s, m, "<@"+m.Author.ID+"> You have opted out, so your messages are not stored or sent to the AI. Use `/privacy opt-in` to change this.")
 			return
 		}
 
-		fmt.Println("Bot mentioned, responding.")
+		err = checkPolicy(ctx, s, r.Db, m.GuildID, m.ChannelID, m.Author.ID, m.Member, policy.MentionFeature)
+		if err != nil {
+			logger.Info("Returning as policy denies the mention", "reason", err)
+			sendToRequester(s, m, "<@"+m.Author.ID+"> "+err.Error())
+			return
+		}
 
-		response := geminiAPIClient.RequestGenAi()
-		fmt.Println("Returning response:", response)
+		inputScreening, err := botRepository.FetchInputScreening(m.GuildID)
+		if err != nil {
+			logger.Error("Failed to fetch screening settings", "error", err)
+		}
+
+		verdict := geminiAPIClient.ScreenInput(inputScreening)
+		if verdict.Blocked {
+			logger.Info("Returning as message was blocked", "stage", verdict.Stage, logging.Content("reason", verdict.Reason))
+
+			blockedReply := inputScreening.BlockedReply
+			if blockedReply == "" {
+				blockedReply = structs.DefaultBlockedReply
+			}
+
+			sendToRequester(s, m, "<@"+m.Author.ID+"> "+blockedReply)
+
+			modLogChannel, err := botRepository.FetchModLogChannel(m.GuildID)
+			if err != nil {
+				logger.Error("Failed to fetch mod-log channel", "error", err)
+			}
+			moderation.LogBlockedMessage(s, modLogChannel, m.Message, verdict.Stage, verdict.Reason)
+			return
+		}
+
+		err = s.ChannelTyping(m.ChannelID)
+		if err != nil {
+			logger.Error("Failed to add typing indicator", "error", err)
+			sendToRequester(s, m, "Failed to respond.")
+			return
+		}
+
+		logger.Info("Bot mentioned, responding")
+
+		reply := geminiAPIClient.RequestGenAi()
+		logger.Debug("Returning response", logging.Content("response", reply.Content))
+
+		if reply.Text == "" {
+			sendToRequester(s, m, reply.Content)
+			return
+		}
+
+		sent, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+			Content:         strings.TruncateString(reply.Content, 2000),
+			Components:      replyComponents(),
+			AllowedMentions: requesterMentions(m.Author.ID),
+			Files:           reply.Files,
+		})
+		if err != nil {
+			logger.Error("Failed to send response", "error", err)
+			return
+		}
+
+		metrics.MentionsAnswered.Inc()
+
+		sendActionConfirmations(ctx, s, sent, reply.Actions)
+
+		err = botRepository.AddConversations(m.GuildID, structs.Conversation{
+			ID:        sent.ID,
+			ChannelID: m.ChannelID,
+			RequestID: m.ID,
+			User: structs.User{
+				ID:          m.Author.ID,
+				Name:        m.Author.DisplayName(),
+				Message:     m.Content,
+				Attachments: reply.Attachments,
+			},
+			Bot: reply.Text,
+		})
+		if err != nil {
+			logger.Error("Failed to store conversation", "error", err)
+		}
+	}
+}
+
+// requesterMentions only lets a reply ping the user who asked, whatever the
+// AI wrote into it.
+func requesterMentions(userID string) *discordgo.MessageAllowedMentions {
+	return &discordgo.MessageAllowedMentions{
+		Users: []string{userID},
+	}
+}
 
-		s.ChannelMessageSend(m.ChannelID, strings.TruncateString(response, 2000))
+func sendToRequester(s *discordgo.Session, m *discordgo.MessageCreate, content string) {
+	_, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:         strings.TruncateString(content, 2000),
+		AllowedMentions: requesterMentions(m.Author.ID),
+	})
+	if err != nil {
+		logging.ForMessage(m.Message).Error("Failed to send message", "error", err)
 	}
 }
@@ -0,0 +1,182 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	gostrings "strings"
+
+	"bot/internal/commands"
+	"bot/internal/logging"
+	"bot/internal/moderation"
+	"bot/internal/response"
+	"bot/internal/storage/mongodb"
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	moderationConfirmPrefix = "mod:confirm:"
+	moderationCancelPrefix  = "mod:cancel:"
+)
+
+// sendActionConfirmations posts a confirmation prompt for each moderation
+// action the model proposed, under the reply that proposed it.
+func sendActionConfirmations(ctx context.Context, s *discordgo.Session, reply *discordgo.Message, actions []structs.ModerationAction) {
+	logger := logging.FromContext(ctx)
+
+	for _, action := range actions {
+		_, err := s.ChannelMessageSendComplex(reply.ChannelID, &discordgo.MessageSend{
+			Content:   confirmationText(action),
+			Reference: reply.Reference(),
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    "Confirm",
+							Style:    discordgo.DangerButton,
+							CustomID: moderationConfirmPrefix + action.ID.Hex(),
+						},
+						discordgo.Button{
+							Label:    "Cancel",
+							Style:    discordgo.SecondaryButton,
+							CustomID: moderationCancelPrefix + action.ID.Hex(),
+						},
+					},
+				},
+			},
+		})
+		if err != nil {
+			logger.Error("Failed to send moderation confirmation", "error", err)
+		}
+	}
+}
+
+func confirmationText(action structs.ModerationAction) string {
+	text := "**" + moderation.Describe(action) + "?**"
+	if action.Reason != "" {
+		text += "\nReason: " + action.Reason
+	}
+
+	return text + fmt.Sprintf("\n-# Only <@%v> can confirm this. It expires <t:%v:R>.", action.RequestedBy, action.ExpiresAt.Unix())
+}
+
+func (r *ComponentParams) handleModerationAction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	logger := logging.FromContext(ctx)
+
+	confirmed := gostrings.HasPrefix(customID, moderationConfirmPrefix)
+	actionID := gostrings.TrimPrefix(gostrings.TrimPrefix(customID, moderationConfirmPrefix), moderationCancelPrefix)
+
+	moderationRepository := mongodb.NewModerationRepository(r.Db)
+
+	action, err := moderationRepository.Fetch(actionID)
+	if err != nil {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Error while fetching the action: %v", err))
+	}
+	if action == nil || action.GuildID != i.GuildID {
+		return response.RespondEphemeral(s, i, "This action no longer exists.")
+	}
+
+	user := commands.InteractionUser(i)
+	if user == nil || user.ID != action.RequestedBy {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Only <@%v> can confirm or cancel this action.", action.RequestedBy))
+	}
+
+	// Permissions may have changed since the action was proposed.
+	if confirmed && (i.Member == nil || !moderation.HasPermission(i.Member.Permissions, action.Action)) {
+		return response.RespondEphemeral(s, i, "You no longer have the permission to do this.")
+	}
+
+	status := structs.ModerationCancelled
+	if confirmed {
+		status = structs.ModerationExecuting
+	}
+
+	resolved, err := moderationRepository.Resolve(actionID, status, user.ID)
+	if err != nil {
+		return response.RespondEphemeral(s, i, fmt.Sprintf("Error while updating the action: %v", err))
+	}
+	if resolved == nil {
+		if action.Status == structs.ModerationPending {
+			return updateConfirmation(s, i, *action, "This action expired before it was confirmed.")
+		}
+		return response.RespondEphemeral(s, i, "This action was already handled.")
+	}
+
+	botRepository := mongodb.NewBotRepository(r.Db)
+
+	modLogChannel, err := botRepository.FetchModLogChannel(i.GuildID)
+	if err != nil {
+		logger.Error("Error while fetching mod-log channel", "error", err)
+	}
+
+	if !confirmed {
+		logger.Info("Moderation action cancelled", "action_id", actionID)
+
+		moderation.Log(s, modLogChannel, *resolved, "")
+		return updateConfirmation(s, i, *resolved, "Cancelled.")
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to defer update: %v", err)
+	}
+
+	logger.Info("Carrying out moderation action", "action_id", actionID, "action", resolved.Action)
+
+	result, actionErr := moderation.Execute(s, *resolved)
+	if actionErr != nil {
+		logger.Warn("Moderation action failed", "action_id", actionID, "error", actionErr)
+
+		resolved.Status = structs.ModerationFailed
+		result = fmt.Sprintf("Failed: %v", actionErr)
+	} else {
+		resolved.Status = structs.ModerationDone
+	}
+
+	err = moderationRepository.Finish(resolved.ID, actionErr)
+	if err != nil {
+		logger.Error("Error while recording moderation result", "error", err)
+	}
+
+	moderation.Log(s, modLogChannel, *resolved, result)
+
+	content := fmt.Sprintf("**%v**\n%v", moderation.Describe(*resolved), result)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Components: &[]discordgo.MessageComponent{},
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update confirmation: %v", err)
+	}
+
+	return nil
+}
+
+// updateConfirmation replaces the confirmation prompt with the outcome and
+// removes its buttons.
+func updateConfirmation(s *discordgo.Session, i *discordgo.InteractionCreate, action structs.ModerationAction, outcome string) error {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    fmt.Sprintf("~~%v~~\n%v", moderation.Describe(action), outcome),
+			Components: []discordgo.MessageComponent{},
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Parse: []discordgo.AllowedMentionType{},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update confirmation: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+
+	"bot/internal/logging"
+	"bot/internal/policy"
+	"bot/internal/storage/mongodb"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// checkPolicy applies the guild policy to a member about to use a feature.
+// Interactions carry the member's permissions, for messages they are resolved
+// through the session.
+func checkPolicy(ctx context.Context, s *discordgo.Session, db *mongo.Database, guildID string, channelID string, userID string, member *discordgo.Member, feature string) error {
+	if guildID == "" || member == nil {
+		return nil
+	}
+
+	logger := logging.FromContext(ctx)
+
+	guildPolicy, err := mongodb.NewBotRepository(db).FetchPolicy(guildID)
+	if err != nil {
+		logger.Error("Error while fetching guild policy", "error", err)
+		return fmt.Errorf("Could not load the permissions for this server.")
+	}
+
+	permissions := member.Permissions
+	if permissions == 0 {
+		permissions, err = s.UserChannelPermissions(userID, channelID)
+		if err != nil {
+			logger.Error("Error while resolving member permissions", "error", err)
+		}
+	}
+
+	return policy.Check(guildPolicy, feature, channelID, member.Roles, permissions)
+}
@@ -0,0 +1,201 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+const (
+	// disconnectGrace is how long the gateway may stay disconnected before
+	// the bot counts as unhealthy. discordgo reconnects on its own, so short
+	// outages are expected.
+	disconnectGrace = 5 * time.Minute
+
+	mongoPingTimeout = 3 * time.Second
+)
+
+// Statuses of a report.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+var lastGeneration atomic.Int64
+
+// RecordGeneration marks that a reply was generated successfully just now.
+func RecordGeneration() {
+	lastGeneration.Store(time.Now().UnixMilli())
+}
+
+// LastGeneration returns when a reply was last generated successfully, or
+// the zero time when none was since the bot started.
+func LastGeneration() time.Time {
+	millis := lastGeneration.Load()
+	if millis == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(millis)
+}
+
+// Checker tracks the Discord gateway through session events and pings
+// MongoDB when asked for a report.
+type Checker struct {
+	Session   *discordgo.Session
+	Mongo     *mongo.Client
+	StartTime time.Time
+
+	mu           sync.Mutex
+	connected    bool
+	stateChanged time.Time
+}
+
+func NewChecker(s *discordgo.Session, mongoClient *mongo.Client, startTime time.Time) *Checker {
+	c := &Checker{
+		Session:      s,
+		Mongo:        mongoClient,
+		StartTime:    startTime,
+		stateChanged: startTime,
+	}
+
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Ready) { c.setConnected(true) })
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Resumed) { c.setConnected(true) })
+	s.AddHandler(func(_ *discordgo.Session, _ *discordgo.Disconnect) { c.setConnected(false) })
+
+	return c
+}
+
+func (c *Checker) setConnected(connected bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connected != connected {
+		c.connected = connected
+		c.stateChanged = time.Now()
+	}
+}
+
+type DiscordReport struct {
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"`
+	LatencyMs int64     `json:"latency_ms,omitempty"`
+}
+
+type MongoReport struct {
+	OK        bool   `json:"ok"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status         string        `json:"status"`
+	Uptime         int64         `json:"uptime"`
+	Discord        DiscordReport `json:"discord"`
+	Mongo          MongoReport   `json:"mongo"`
+	LastGeneration *time.Time    `json:"last_generation,omitempty"`
+}
+
+// Ready reports whether the bot can answer messages right now.
+func (r Report) Ready() bool {
+	return r.Discord.Connected && r.Mongo.OK
+}
+
+// Check builds a report of the current state. The bot is down when the
+// gateway has been disconnected for longer than the grace period, and
+// degraded while it reconnects or MongoDB cannot be reached.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	discord := DiscordReport{Connected: c.connected, Since: c.stateChanged}
+	c.mu.Unlock()
+
+	if discord.Connected {
+		discord.LatencyMs = c.Session.HeartbeatLatency().Milliseconds()
+	}
+
+	report := Report{
+		Uptime:  time.Since(c.StartTime).Milliseconds(),
+		Discord: discord,
+		Mongo:   c.pingMongo(ctx),
+	}
+
+	if last := LastGeneration(); !last.IsZero() {
+		report.LastGeneration = &last
+	}
+
+	switch {
+	case report.Ready():
+		report.Status = StatusOK
+	case !discord.Connected && time.Since(discord.Since) > disconnectGrace:
+		report.Status = StatusDown
+	default:
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
+
+func (c *Checker) pingMongo(ctx context.Context) MongoReport {
+	ctx, cancel := context.WithTimeout(ctx, mongoPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Mongo.Ping(ctx, readpref.Primary())
+
+	report := MongoReport{OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		// Driver errors can name hosts and the cluster topology, and the
+		// report is public, so only the log gets the details.
+		slog.Warn("MongoDB ping failed", "error", err)
+		report.Error = "database unreachable"
+	}
+
+	return report
+}
+
+// Healthz answers whether the bot should be restarted. It only fails once
+// the bot is down, so that a reconnect or a database outage does not cause
+// restarts that would not help.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, report)
+}
+
+// Readyz answers whether the bot can handle messages, with the Discord
+// gateway connected and MongoDB reachable.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, status, report)
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		slog.Error("Error while writing health report", "error", err)
+	}
+}
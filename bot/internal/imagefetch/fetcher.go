@@ -0,0 +1,158 @@
+package imagefetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// extensions maps the image types Discord can display to their file extension.
+var extensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Extension returns the file extension for an image content type, and false
+// when the type is not one Discord can display.
+func Extension(contentType string) (string, bool) {
+	extension, ok := extensions[contentType]
+	return extension, ok
+}
+
+// ContentType returns the image content type for a file extension.
+func ContentType(extension string) (string, bool) {
+	for contentType, ext := range extensions {
+		if ext == extension {
+			return contentType, true
+		}
+	}
+
+	return "", false
+}
+
+type Fetcher struct {
+	Client      *http.Client
+	Concurrency int
+	Timeout     time.Duration
+	MaxBytes    int64
+}
+
+// Image is the outcome of fetching one URL. Err is set when the download
+// failed or the response was not an acceptable image.
+type Image struct {
+	URL         string
+	Data        []byte
+	ContentType string
+	Extension   string
+	Err         error
+}
+
+func NewFetcher() *Fetcher {
+	return &Fetcher{
+		Client:      &http.Client{},
+		Concurrency: 4,
+		Timeout:     15 * time.Second,
+		MaxBytes:    8 << 20,
+	}
+}
+
+// FetchAll downloads the URLs with at most Concurrency requests in flight. The
+// results are in the same order as the URLs, failures included, so callers can
+// keep whatever succeeded.
+func (f *Fetcher) FetchAll(ctx context.Context, urls []string) []Image {
+	images := make([]Image, len(urls))
+
+	concurrency := f.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for idx, url := range urls {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				images[idx] = Image{URL: url, Err: ctx.Err()}
+				return
+			}
+
+			images[idx] = f.Fetch(ctx, url)
+		}()
+	}
+
+	wg.Wait()
+
+	return images
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, url string) Image {
+	image := Image{URL: url}
+
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		image.Err = fmt.Errorf("failed to create request: %v", err)
+		return image
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		image.Err = fmt.Errorf("failed to fetch image: %v", err)
+		return image
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		image.Err = fmt.Errorf("failed to fetch image: status %v", resp.StatusCode)
+		return image
+	}
+
+	if resp.ContentLength > f.MaxBytes {
+		image.Err = fmt.Errorf("image is too large (%v bytes)", resp.ContentLength)
+		return image
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxBytes+1))
+	if err != nil {
+		image.Err = fmt.Errorf("failed to read image: %v", err)
+		return image
+	}
+	if int64(len(data)) > f.MaxBytes {
+		image.Err = fmt.Errorf("image is larger than %v bytes", f.MaxBytes)
+		return image
+	}
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || extensions[contentType] == "" {
+		// Fall back to the content itself when the header is missing or vague.
+		contentType = http.DetectContentType(data)
+	}
+
+	extension, ok := extensions[contentType]
+	if !ok {
+		image.Err = fmt.Errorf("unsupported content type '%v'", contentType)
+		return image
+	}
+
+	image.Data = data
+	image.ContentType = contentType
+	image.Extension = extension
+
+	return image
+}
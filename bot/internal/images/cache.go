@@ -0,0 +1,195 @@
+package images
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"bot/internal/imagefetch"
+)
+
+// DiskCache keeps recently fetched images on disk, evicting the least recently
+// used ones once MaxBytes is exceeded. Access times are kept in the file
+// modification times so the order survives restarts.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+	size    int64
+}
+
+// cacheExtensions are the file extensions the cache stores images with.
+var cacheExtensions = []string{".png", ".jpg", ".gif", ".webp"}
+
+type cacheEntry struct {
+	name string
+	size int64
+}
+
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image cache directory: %w", err)
+	}
+
+	cache := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image cache directory: %w", err)
+	}
+
+	type existingFile struct {
+		name    string
+		size    int64
+		modTime time.Time
+	}
+
+	files := make([]existingFile, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		// The directory may be shared, so only files the cache wrote
+		// itself are counted and evicted.
+		if err != nil || !info.Mode().IsRegular() || !isCacheName(dirEntry.Name()) {
+			continue
+		}
+		files = append(files, existingFile{name: dirEntry.Name(), size: info.Size(), modTime: info.ModTime()})
+	}
+
+	// Oldest first, so the most recently used file ends up at the front.
+	slices.SortFunc(files, func(a, b existingFile) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	for _, file := range files {
+		cache.entries[file.name] = cache.order.PushFront(&cacheEntry{name: file.name, size: file.size})
+		cache.size += file.size
+	}
+
+	cache.mu.Lock()
+	cache.evict()
+	cache.mu.Unlock()
+
+	return cache, nil
+}
+
+func cacheName(url string, extension string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:]) + extension
+}
+
+// isCacheName reports whether a file name has the form cacheName produces.
+func isCacheName(name string) bool {
+	extension := filepath.Ext(name)
+	if !slices.Contains(cacheExtensions, extension) {
+		return false
+	}
+
+	key := strings.TrimSuffix(name, extension)
+	if len(key) != hex.EncodedLen(sha256.Size) || strings.ToLower(key) != key {
+		return false
+	}
+
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// Get returns the cached image for a URL along with its content type.
+func (c *DiskCache) Get(url string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prefix := cacheName(url, "")
+
+	for _, extension := range cacheExtensions {
+		element, ok := c.entries[prefix+extension]
+		if !ok {
+			continue
+		}
+
+		entry := element.Value.(*cacheEntry)
+		path := filepath.Join(c.dir, entry.name)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			c.remove(element)
+			return nil, "", false
+		}
+
+		c.order.MoveToFront(element)
+		now := time.Now()
+		os.Chtimes(path, now, now)
+
+		contentType, _ := imagefetch.ContentType(extension)
+		return data, contentType, true
+	}
+
+	return nil, "", false
+}
+
+func (c *DiskCache) Put(url string, data []byte, contentType string) {
+	extension, ok := imagefetch.Extension(contentType)
+	if !ok || int64(len(data)) > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := cacheName(url, extension)
+	if element, ok := c.entries[name]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+
+	err := os.WriteFile(filepath.Join(c.dir, name), data, 0o644)
+	if err != nil {
+		slog.Error("Error while writing image to cache", "error", err)
+		return
+	}
+
+	c.entries[name] = c.order.PushFront(&cacheEntry{name: name, size: int64(len(data))})
+	c.size += int64(len(data))
+
+	c.evict()
+}
+
+// evict must be called with mu held.
+func (c *DiskCache) evict() {
+	for c.size > c.maxBytes {
+		oldest := c.order.Back()
+		if oldest == nil {
+			return
+		}
+		c.remove(oldest)
+	}
+}
+
+func (c *DiskCache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+
+	err := os.Remove(filepath.Join(c.dir, entry.name))
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("Error while removing image from cache", "error", err)
+	}
+
+	c.order.Remove(element)
+	delete(c.entries, entry.name)
+	c.size -= entry.size
+}
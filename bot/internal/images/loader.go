@@ -0,0 +1,68 @@
+package images
+
+import (
+	"context"
+
+	"bot/internal/imagefetch"
+)
+
+// Loader turns provider results into image data, downloading remote images
+// through the fetcher and the cache when one is configured.
+type Loader struct {
+	Fetcher *imagefetch.Fetcher
+	Cache   *DiskCache
+}
+
+func NewLoader(cache *DiskCache) *Loader {
+	return &Loader{
+		Fetcher: imagefetch.NewFetcher(),
+		Cache:   cache,
+	}
+}
+
+// Load returns one result per image in the same order, failures included.
+func (l *Loader) Load(ctx context.Context, images []Image) []imagefetch.Image {
+	loaded := make([]imagefetch.Image, len(images))
+
+	missing := make([]string, 0, len(images))
+	missingIndexes := make([]int, 0, len(images))
+
+	for idx, image := range images {
+		if image.Data != nil {
+			extension, _ := imagefetch.Extension(image.ContentType)
+			loaded[idx] = imagefetch.Image{
+				URL:         image.URL,
+				Data:        image.Data,
+				ContentType: image.ContentType,
+				Extension:   extension,
+			}
+			continue
+		}
+
+		if l.Cache != nil {
+			if data, contentType, ok := l.Cache.Get(image.URL); ok {
+				extension, _ := imagefetch.Extension(contentType)
+				loaded[idx] = imagefetch.Image{
+					URL:         image.URL,
+					Data:        data,
+					ContentType: contentType,
+					Extension:   extension,
+				}
+				continue
+			}
+		}
+
+		missing = append(missing, image.URL)
+		missingIndexes = append(missingIndexes, idx)
+	}
+
+	for idx, fetched := range l.Fetcher.FetchAll(ctx, missing) {
+		loaded[missingIndexes[idx]] = fetched
+
+		if fetched.Err == nil && l.Cache != nil {
+			l.Cache.Put(fetched.URL, fetched.Data, fetched.ContentType)
+		}
+	}
+
+	return loaded
+}
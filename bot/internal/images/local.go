@@ -0,0 +1,96 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"bot/internal/imagefetch"
+)
+
+// LocalProvider serves images from a directory with one subdirectory per
+// category, so self-hosters can run without nekos.best.
+type LocalProvider struct {
+	Dir string
+}
+
+func NewLocalProvider(dir string) *LocalProvider {
+	return &LocalProvider{
+		Dir: dir,
+	}
+}
+
+func (p *LocalProvider) Name() string {
+	return "local library"
+}
+
+func (p *LocalProvider) Categories() []string {
+	entries, err := os.ReadDir(p.Dir)
+	if err != nil {
+		slog.Error("Error while reading local image directory", "error", err)
+		return nil
+	}
+
+	categories := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			categories = append(categories, entry.Name())
+		}
+	}
+
+	return categories
+}
+
+func (p *LocalProvider) Fetch(ctx context.Context, category string, count int) ([]Image, error) {
+	categoryDir := filepath.Join(p.Dir, filepath.Base(category))
+
+	entries, err := os.ReadDir(categoryDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category '%v': %v", category, err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := imagefetch.ContentType(filepath.Ext(entry.Name())); ok && entry.Type().IsRegular() {
+			files = append(files, entry.Name())
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no images in category '%v'", category)
+	}
+
+	rand.Shuffle(len(files), func(a, b int) {
+		files[a], files[b] = files[b], files[a]
+	})
+
+	images := make([]Image, 0, count)
+
+	for idx := 0; idx < count; idx++ {
+		// Repeat files when the category has fewer images than requested.
+		name := files[idx%len(files)]
+
+		data, err := os.ReadFile(filepath.Join(categoryDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image '%v': %v", name, err)
+		}
+
+		contentType := http.DetectContentType(data)
+		if _, ok := imagefetch.Extension(contentType); !ok {
+			slog.Warn("Skipping local image", "name", name, "content_type", contentType)
+			continue
+		}
+
+		images = append(images, Image{
+			Data:        data,
+			ContentType: contentType,
+			Attribution: fmt.Sprintf("From the local image library (%v).", name),
+		})
+	}
+
+	return images, nil
+}
@@ -0,0 +1,103 @@
+package images
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Image is a single result from a provider. Providers that serve remote
+// images set URL and leave downloading to the Loader, local ones set Data and
+// ContentType directly.
+type Image struct {
+	URL         string
+	Data        []byte
+	ContentType string
+
+	// Attribution is shown under the image, in Discord markdown.
+	Attribution string
+	Artist      string
+}
+
+type ImageProvider interface {
+	Name() string
+	Categories() []string
+	Fetch(ctx context.Context, category string, count int) ([]Image, error)
+}
+
+// SafeProvider is implemented by providers whose categories are known to be
+// safe for work, so they may be posted outside age-restricted channels.
+type SafeProvider interface {
+	SafeCategories() []string
+}
+
+// Searcher is implemented by providers that can look up images of a category
+// by artist.
+type Searcher interface {
+	Search(ctx context.Context, query string, category string, count int) ([]Image, error)
+}
+
+type Registry struct {
+	Providers []ImageProvider
+	Loader    *Loader
+}
+
+func NewRegistry(loader *Loader, providers ...ImageProvider) *Registry {
+	return &Registry{
+		Providers: providers,
+		Loader:    loader,
+	}
+}
+
+// Categories lists the categories of every provider in order, skipping ones
+// already offered by an earlier provider.
+func (r *Registry) Categories() []string {
+	categories := []string{}
+
+	for _, provider := range r.Providers {
+		for _, category := range provider.Categories() {
+			if !slices.Contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+	}
+
+	return categories
+}
+
+// Provider returns the first provider offering a category, or nil.
+func (r *Registry) Provider(category string) ImageProvider {
+	for _, provider := range r.Providers {
+		if slices.Contains(provider.Categories(), category) {
+			return provider
+		}
+	}
+
+	return nil
+}
+
+// IsSafe reports whether the provider of a category marks it as safe for work.
+func (r *Registry) IsSafe(category string) bool {
+	provider, ok := r.Provider(category).(SafeProvider)
+	return ok && slices.Contains(provider.SafeCategories(), category)
+}
+
+// Choices returns the categories as command option choices, capped at the 25
+// choices Discord allows.
+func (r *Registry) Choices() []*discordgo.ApplicationCommandOptionChoice {
+	categories := r.Categories()
+	if len(categories) > 25 {
+		categories = categories[:25]
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(categories))
+	for _, category := range categories {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  category,
+			Value: category,
+		})
+	}
+
+	return choices
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// The correlation ID of a request is the ID of the Discord message or
+// interaction that started it. It is unique, and any layer that has the
+// message can find the same ID again.
+
+// ForMessage returns a logger for handling the message.
+func ForMessage(m *discordgo.Message) *slog.Logger {
+	logger := slog.Default().With(
+		slog.String("correlation_id", m.ID),
+		slog.String("guild_id", m.GuildID),
+		slog.String("channel_id", m.ChannelID),
+	)
+	if m.Author != nil {
+		logger = logger.With(slog.String("user_id", m.Author.ID))
+	}
+
+	return logger
+}
+
+// ForInteraction returns a logger for handling the interaction.
+func ForInteraction(i *discordgo.InteractionCreate) *slog.Logger {
+	logger := slog.Default().With(
+		slog.String("correlation_id", i.ID),
+		slog.String("guild_id", i.GuildID),
+		slog.String("channel_id", i.ChannelID),
+	)
+
+	if i.Member != nil && i.Member.User != nil {
+		logger = logger.With(slog.String("user_id", i.Member.User.ID))
+	} else if i.User != nil {
+		logger = logger.With(slog.String("user_id", i.User.ID))
+	}
+
+	return logger
+}
+
+// MessageContext returns a context carrying the logger for the message.
+func MessageContext(m *discordgo.Message) context.Context {
+	return WithLogger(context.Background(), ForMessage(m))
+}
+
+// InteractionContext returns a context carrying the logger for the
+// interaction.
+func InteractionContext(i *discordgo.InteractionCreate) context.Context {
+	return WithLogger(context.Background(), ForInteraction(i))
+}
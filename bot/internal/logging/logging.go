@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// debug turns off the redaction of message content. Secrets stay redacted.
+var debug bool
+
+// Setup configures the default logger from the environment. LOG_LEVEL is
+// debug, info, warn or error, LOG_FORMAT is json or text, and LOG_DEBUG=true
+// logs at debug level with message content included.
+func Setup() {
+	setup(os.Stdout, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"), os.Getenv("LOG_DEBUG") == "true")
+}
+
+func setup(w io.Writer, level string, format string, debugMode bool) {
+	debug = debugMode
+
+	var leveler slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		leveler = slog.LevelDebug
+	case "warn", "warning":
+		leveler = slog.LevelWarn
+	case "error":
+		leveler = slog.LevelError
+	default:
+		leveler = slog.LevelInfo
+	}
+	if debugMode {
+		leveler = slog.LevelDebug
+	}
+
+	options := &slog.HandlerOptions{
+		Level:       leveler,
+		ReplaceAttr: redactSecrets,
+	}
+
+	var handler slog.Handler
+	if strings.ToLower(format) == "text" {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	slog.SetDefault(slog.New(handler))
+
+	// Libraries that use the standard logger, such as discordgo, end up in
+	// the same output.
+	log.SetFlags(0)
+	log.SetOutput(slog.NewLogLogger(handler, slog.LevelInfo).Writer())
+}
+
+// secretKeys are attribute names that are never logged.
+var secretKeys = map[string]bool{
+	"token":         true,
+	"api_key":       true,
+	"apikey":        true,
+	"secret":        true,
+	"password":      true,
+	"authorization": true,
+}
+
+// secretParams matches credentials in URLs, which end up in the errors of
+// failed HTTP requests, such as the appid of OpenWeatherMap.
+var secretParams = regexp.MustCompile(`(?i)\b(appid|api_key|apikey|key|token|secret)=[^&\s"']+`)
+
+// redactSecrets is the handlers' ReplaceAttr. Secrets are redacted in debug
+// mode too.
+func redactSecrets(groups []string, attr slog.Attr) slog.Attr {
+	if secretKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, "[redacted]")
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, redactParams(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, redactParams(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+func redactParams(value string) string {
+	return secretParams.ReplaceAllString(value, "$1=[redacted]")
+}
+
+type contentValue string
+
+// LogValue hides the content unless debug mode is on, keeping its length so
+// logs still show whether something was empty or huge.
+func (v contentValue) LogValue() slog.Value {
+	if debug {
+		return slog.StringValue(string(v))
+	}
+
+	return slog.GroupValue(slog.Bool("redacted", true), slog.Int("length", len(v)))
+}
+
+// Content marks text written by users or the model, such as prompts and
+// replies. It is only logged in debug mode.
+func Content(key string, value string) slog.Attr {
+	return slog.Any(key, contentValue(value))
+}
+
+type contextKey struct{}
+
+// WithLogger returns a context carrying the logger, so that the layers a
+// request passes through log with its correlation ID.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the request's logger, or the default logger outside
+// of a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+
+	return slog.Default()
+}
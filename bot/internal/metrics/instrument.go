@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// MongoMonitor times every command the MongoDB client sends.
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			MongoLatency.WithLabelValues(e.CommandName, OutcomeOK).Observe(e.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			MongoLatency.WithLabelValues(e.CommandName, OutcomeError).Observe(e.Duration.Seconds())
+		},
+	}
+}
+
+type discordTransport struct {
+	next http.RoundTripper
+}
+
+func (t discordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		DiscordAPIErrors.WithLabelValues("network").Inc()
+		return resp, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		DiscordAPIErrors.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
+	}
+
+	return resp, nil
+}
+
+// InstrumentSession counts the errors of the session's REST requests, and
+// reports the guilds the session is in.
+func InstrumentSession(s *discordgo.Session) {
+	next := s.Client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	s.Client.Transport = discordTransport{next: next}
+
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_guilds",
+		Help:      "Guilds the bot is in that are currently available.",
+	}, func() float64 {
+		s.State.RLock()
+		defer s.State.RUnlock()
+
+		var available int
+		for _, guild := range s.State.Guilds {
+			if !guild.Unavailable {
+				available++
+			}
+		}
+
+		return float64(available)
+	}))
+}
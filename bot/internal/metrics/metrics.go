@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/genai"
+)
+
+const namespace = "cordfriend"
+
+var (
+	MessagesHandled = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_handled_total",
+		Help:      "Messages from other users the bot received.",
+	})
+
+	MentionsAnswered = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mentions_answered_total",
+		Help:      "Mentions the bot answered with a generated reply.",
+	})
+
+	GeminiLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gemini_request_duration_seconds",
+		Help:      "Duration of Gemini API requests.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"operation"})
+
+	GeminiErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gemini_errors_total",
+		Help:      "Failed Gemini API requests by error class.",
+	}, []string{"operation", "class"})
+
+	ToolCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Tool calls made by the model by tool and outcome.",
+	}, []string{"tool", "outcome"})
+
+	MongoLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "Duration of MongoDB commands.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"command", "outcome"})
+
+	DiscordAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "discord_api_errors_total",
+		Help:      "Failed Discord REST requests by status code, or 'network' when no response arrived.",
+	}, []string{"status"})
+
+	QueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "generation_queue_depth",
+		Help:      "Replies that are currently being generated.",
+	})
+)
+
+// Tool call outcomes.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveGemini records the duration of a Gemini request that started at
+// start, and its error class when it failed.
+func ObserveGemini(operation string, start time.Time, err error) {
+	GeminiLatency.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		GeminiErrors.WithLabelValues(operation, ErrorClass(err)).Inc()
+	}
+}
+
+// ErrorClass groups Gemini errors into a few classes, so that rate limits
+// and bad keys can be told apart from outages without a label per message.
+func ErrorClass(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return "other"
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		return "rate_limited"
+	case apiErr.Code == http.StatusUnauthorized, apiErr.Code == http.StatusForbidden:
+		return "auth"
+	case apiErr.Code >= 500:
+		return "server"
+	case apiErr.Code >= 400:
+		return "invalid_request"
+	}
+
+	return "other"
+}
+
+// RecordToolCall counts a tool call by its outcome.
+func RecordToolCall(tool string, failed bool) {
+	outcome := OutcomeOK
+	if failed {
+		outcome = OutcomeError
+	}
+
+	ToolCalls.WithLabelValues(tool, outcome).Inc()
+}
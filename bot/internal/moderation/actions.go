@@ -0,0 +1,237 @@
+package moderation
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConfirmationTimeout is how long a proposed action can be confirmed.
+const ConfirmationTimeout = 10 * time.Minute
+
+const (
+	// MaxTimeoutMinutes is the longest timeout Discord allows, 28 days.
+	MaxTimeoutMinutes = 28 * 24 * 60
+
+	// MaxDeletedMessages is how many messages can be deleted at once.
+	MaxDeletedMessages = 100
+)
+
+// bulkDeleteAge is the age after which Discord refuses to bulk delete a
+// message.
+const bulkDeleteAge = 14 * 24 * time.Hour
+
+// Permission returns the Discord permission a member needs to request the
+// action.
+func Permission(action string) int64 {
+	switch action {
+	case structs.ModerationTimeout:
+		return discordgo.PermissionModerateMembers
+	case structs.ModerationDeleteMessages:
+		return discordgo.PermissionManageMessages
+	case structs.ModerationAddRole:
+		return discordgo.PermissionManageRoles
+	}
+
+	return discordgo.PermissionAdministrator
+}
+
+// HasPermission reports whether the permissions allow the action.
+func HasPermission(permissions int64, action string) bool {
+	return permissions&discordgo.PermissionAdministrator != 0 || permissions&Permission(action) != 0
+}
+
+// Describe returns a one-line summary of the action for the confirmation
+// message and the mod-log.
+func Describe(action structs.ModerationAction) string {
+	switch action.Action {
+	case structs.ModerationTimeout:
+		return fmt.Sprintf("Time out <@%v> for %v", action.TargetID, minutesText(action.Minutes))
+	case structs.ModerationDeleteMessages:
+		if action.TargetID != "" {
+			return fmt.Sprintf("Delete the last %v message(s) from <@%v> in <#%v>", action.Count, action.TargetID, action.ChannelID)
+		}
+		return fmt.Sprintf("Delete the last %v message(s) in <#%v>", action.Count, action.ChannelID)
+	case structs.ModerationAddRole:
+		return fmt.Sprintf("Give <@%v> the <@&%v> role", action.TargetID, action.RoleID)
+	}
+
+	return action.Action
+}
+
+func minutesText(minutes int) string {
+	switch {
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%v day(s)", minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%v hour(s)", minutes/60)
+	}
+
+	return fmt.Sprintf("%v minute(s)", minutes)
+}
+
+// CheckHierarchy refuses actions on members or roles at or above the
+// requester's highest role, which Discord would let the bot do on their
+// behalf if the bot's own role is higher. The server owner is exempt.
+func CheckHierarchy(s *discordgo.Session, action structs.ModerationAction) error {
+	if action.Action == structs.ModerationDeleteMessages {
+		return nil
+	}
+
+	guild, err := s.State.Guild(action.GuildID)
+	if err != nil {
+		guild, err = s.Guild(action.GuildID)
+		if err != nil {
+			return fmt.Errorf("could not load the server: %v", err)
+		}
+	}
+
+	if action.TargetID == guild.OwnerID {
+		return fmt.Errorf("the server owner cannot be moderated")
+	}
+	if action.RequestedBy == guild.OwnerID {
+		return nil
+	}
+
+	requester, err := member(s, action.GuildID, action.RequestedBy)
+	if err != nil {
+		return err
+	}
+	requesterPosition := highestPosition(guild.Roles, requester.Roles)
+
+	switch action.Action {
+	case structs.ModerationTimeout:
+		target, err := member(s, action.GuildID, action.TargetID)
+		if err != nil {
+			return err
+		}
+
+		if highestPosition(guild.Roles, target.Roles) >= requesterPosition {
+			return fmt.Errorf("<@%v> has a role as high as or higher than yours", action.TargetID)
+		}
+	case structs.ModerationAddRole:
+		index := slices.IndexFunc(guild.Roles, func(role *discordgo.Role) bool {
+			return role.ID == action.RoleID
+		})
+		if index == -1 {
+			return fmt.Errorf("the role no longer exists")
+		}
+
+		role := guild.Roles[index]
+		if role.ID == guild.ID || role.Managed {
+			return fmt.Errorf("<@&%v> cannot be given to members", role.ID)
+		}
+		if role.Position >= requesterPosition {
+			return fmt.Errorf("<@&%v> is as high as or higher than your highest role", role.ID)
+		}
+	}
+
+	return nil
+}
+
+func member(s *discordgo.Session, guildID string, userID string) (*discordgo.Member, error) {
+	if member, err := s.State.Member(guildID, userID); err == nil {
+		return member, nil
+	}
+
+	member, err := s.GuildMember(guildID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("<@%v> is not in this server", userID)
+	}
+
+	return member, nil
+}
+
+func highestPosition(roles []*discordgo.Role, memberRoles []string) int {
+	highest := 0
+	for _, role := range roles {
+		if role.Position > highest && slices.Contains(memberRoles, role.ID) {
+			highest = role.Position
+		}
+	}
+
+	return highest
+}
+
+// Execute carries out a confirmed action and returns what was done.
+func Execute(s *discordgo.Session, action structs.ModerationAction) (string, error) {
+	err := CheckHierarchy(s, action)
+	if err != nil {
+		return "", err
+	}
+
+	reason := discordgo.WithAuditLogReason(auditReason(action))
+
+	switch action.Action {
+	case structs.ModerationTimeout:
+		until := time.Now().Add(time.Duration(action.Minutes) * time.Minute)
+
+		err := s.GuildMemberTimeout(action.GuildID, action.TargetID, &until, reason)
+		if err != nil {
+			return "", fmt.Errorf("failed to time out member: %v", err)
+		}
+
+		return fmt.Sprintf("<@%v> is timed out until <t:%v:f>.", action.TargetID, until.Unix()), nil
+	case structs.ModerationDeleteMessages:
+		return deleteMessages(s, action, reason)
+	case structs.ModerationAddRole:
+		err := s.GuildMemberRoleAdd(action.GuildID, action.TargetID, action.RoleID, reason)
+		if err != nil {
+			return "", fmt.Errorf("failed to add role: %v", err)
+		}
+
+		return fmt.Sprintf("<@%v> now has the <@&%v> role.", action.TargetID, action.RoleID), nil
+	}
+
+	return "", fmt.Errorf("unknown action '%v'", action.Action)
+}
+
+// auditReason shows who confirmed the action in Discord's audit log, since
+// the bot is the one carrying it out.
+func auditReason(action structs.ModerationAction) string {
+	reason := "Requested by " + action.RequestedBy
+	if action.Reason != "" {
+		reason += ": " + action.Reason
+	}
+
+	return reason
+}
+
+// deleteMessages deletes the most recent messages sent before the request,
+// so the request, the bot's reply and the confirmation are not counted.
+func deleteMessages(s *discordgo.Session, action structs.ModerationAction, reason discordgo.RequestOption) (string, error) {
+	messages, err := s.ChannelMessages(action.ChannelID, MaxDeletedMessages, action.RequestID, "", "")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch messages: %v", err)
+	}
+
+	var messageIDs []string
+	for _, message := range messages {
+		if len(messageIDs) == action.Count || time.Since(message.Timestamp) > bulkDeleteAge {
+			break
+		}
+		if action.TargetID != "" && message.Author.ID != action.TargetID {
+			continue
+		}
+
+		messageIDs = append(messageIDs, message.ID)
+	}
+
+	switch len(messageIDs) {
+	case 0:
+		return "", fmt.Errorf("no messages newer than 14 days were found")
+	case 1:
+		err = s.ChannelMessageDelete(action.ChannelID, messageIDs[0], reason)
+	default:
+		err = s.ChannelMessagesBulkDelete(action.ChannelID, messageIDs, reason)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to delete messages: %v", err)
+	}
+
+	return fmt.Sprintf("Deleted %v message(s) in <#%v>.", len(messageIDs), action.ChannelID), nil
+}
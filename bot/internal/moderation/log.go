@@ -0,0 +1,91 @@
+package moderation
+
+import (
+	"log/slog"
+	"time"
+
+	"bot/internal/strings"
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	colorDone      = 0x57f287
+	colorFailed    = 0xed4245
+	colorCancelled = 0x95a5a6
+)
+
+// Log posts a resolved action to the guild's mod-log channel. Nothing is
+// posted when no channel is configured.
+func Log(s *discordgo.Session, channelID string, action structs.ModerationAction, result string) {
+	if channelID == "" {
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Description: Describe(action),
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Requested by", Value: "<@" + action.RequestedBy + ">", Inline: true},
+		},
+	}
+
+	switch action.Status {
+	case structs.ModerationDone:
+		embed.Title = "Moderation action carried out"
+		embed.Color = colorDone
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Confirmed by", Value: "<@" + action.ResolvedBy + ">", Inline: true})
+	case structs.ModerationFailed:
+		embed.Title = "Moderation action failed"
+		embed.Color = colorFailed
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Confirmed by", Value: "<@" + action.ResolvedBy + ">", Inline: true})
+	default:
+		embed.Title = "Moderation action cancelled"
+		embed.Color = colorCancelled
+	}
+
+	if action.Reason != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Reason", Value: strings.TruncateString(action.Reason, 1024)})
+	}
+	if result != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Result", Value: strings.TruncateString(result, 1024)})
+	}
+
+	postEmbed(s, channelID, embed)
+}
+
+// LogBlockedMessage posts a message that was kept from the model to the
+// guild's mod-log channel.
+func LogBlockedMessage(s *discordgo.Session, channelID string, m *discordgo.Message, stage string, reason string) {
+	if channelID == "" {
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Message blocked",
+		Description: strings.TruncateString(m.Content, 4096),
+		Color:       colorFailed,
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Author", Value: "<@" + m.Author.ID + ">", Inline: true},
+			{Name: "Channel", Value: "<#" + m.ChannelID + ">", Inline: true},
+			{Name: "Check", Value: stage, Inline: true},
+			{Name: "Reason", Value: strings.TruncateString(reason, 1024)},
+		},
+	}
+
+	postEmbed(s, channelID, embed)
+}
+
+func postEmbed(s *discordgo.Session, channelID string, embed *discordgo.MessageEmbed) {
+	_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Parse: []discordgo.AllowedMentionType{},
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to post to the mod-log", "error", err)
+	}
+}
@@ -1,79 +1,267 @@
 package gemini
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"bot/internal/health"
+	"bot/internal/imagefetch"
+	"bot/internal/logging"
+	"bot/internal/metrics"
+	"bot/internal/moderation"
 	"bot/internal/platform/gemini/tools"
+	"bot/internal/search"
 	"bot/internal/storage/mongodb"
 	"bot/internal/structs"
 
 	"github.com/bwmarrin/discordgo"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"google.golang.org/genai"
 )
 
 type APIRequest struct {
+	Session    *discordgo.Session
 	Repository *mongodb.BotRepository
+	Privacy    *mongodb.PrivacyRepository
+	Quota      *mongodb.QuotaRepository
+	Reminders  *mongodb.ReminderRepository
+	Moderation *mongodb.ModerationRepository
+	Usage      *mongodb.UsageRepository
 	M          *discordgo.MessageCreate
+
+	// ctx carries the logger with the correlation ID of the message or
+	// interaction the request is for.
+	ctx context.Context
+
+	// ExcludeConversation leaves the stored conversation with this ID out of
+	// the history sent to the model. It is set when regenerating a reply so the
+	// answer being replaced does not influence the new one.
+	ExcludeConversation string
+}
+
+// Reply is the result of a generation request. Content is the message to post
+// in the channel. Text is the model's answer on its own and is empty when
+// generation failed, in which case Content holds the error for the user.
+type Reply struct {
+	Content string
+	Text    string
+
+	// Attachments describes the files that were sent to the model with the
+	// message.
+	Attachments []structs.Attachment
+
+	// Files are generated by tools and should be attached to the reply.
+	Files []*discordgo.File
+
+	// Sources are the URLs of web search results the model was given, in
+	// the order it numbered them.
+	Sources []string
+
+	// Actions are moderation actions the model proposed. Each one needs to
+	// be confirmed by the requester before it is carried out.
+	Actions []structs.ModerationAction
 }
 
-func NewAPIRequest(repository *mongodb.BotRepository, m *discordgo.MessageCreate) *APIRequest {
+// maxMessageLength is the longest message Discord accepts.
+const maxMessageLength = 2000
+
+// maxToolRounds is how often the model may call tools before it has to
+// answer, so a model that keeps chaining calls cannot loop forever.
+const maxToolRounds = 5
+
+func NewAPIRequest(ctx context.Context, s *discordgo.Session, db *mongo.Database, m *discordgo.MessageCreate) *APIRequest {
 	return &APIRequest{
-		Repository: repository,
+		ctx:        ctx,
+		Session:    s,
+		Repository: mongodb.NewBotRepository(db),
+		Privacy:    mongodb.NewPrivacyRepository(db),
+		Quota:      mongodb.NewQuotaRepository(db),
+		Reminders:  mongodb.NewReminderRepository(db),
+		Moderation: mongodb.NewModerationRepository(db),
+		Usage:      mongodb.NewUsageRepository(db),
 		M:          m,
 	}
 }
 
-func (r *APIRequest) RequestGenAi() string {
-	fmt.Println("Generating response...")
+func (r *APIRequest) logger() *slog.Logger {
+	return logging.FromContext(r.ctx)
+}
 
-	apiKey, err := r.Repository.FetchApiKey(r.M.GuildID)
-	if err != nil {
-		fmt.Println("Error while fetching API key:", err)
-		return "Could not fetch API key for this server."
+// stringArg reads an optional string argument of a function call.
+func stringArg(args map[string]any, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func failedReply(message string) Reply {
+	return Reply{Content: message}
+}
+
+// failedResult reports whether a tool result tells the model about an error.
+func failedResult(result map[string]any) bool {
+	_, failed := result["error"]
+	return failed
+}
+
+func (r *APIRequest) RequestGenAi() Reply {
+	r.logger().Info("Generating response")
+
+	var sentUserId = r.M.Author.ID
+
+	attachmentParts, attachments := r.attachmentParts(r.ctx)
+
+	promptToSend := r.buildPrompt(attachments, "")
+
+	reply := r.generate(promptToSend, append(attachmentParts, r.linkParts(r.ctx)...))
+	if reply.Text != "" {
+		reply.Text = r.filterOutput(reply.Text)
+		reply.Content = withSources("<@"+sentUserId+"> "+reply.Text, reply.Sources)
+		reply.Attachments = attachments
 	}
-	if apiKey == "" {
-		fmt.Println("Could not fetch API key.")
-		return "Could not fetch API key for this server."
+
+	return reply
+}
+
+// RequestContinuation asks the model to carry on from a previous answer to the
+// same message, for replies that were cut off.
+func (r *APIRequest) RequestContinuation(previous string) Reply {
+	r.logger().Info("Generating continuation")
+
+	attachmentParts, attachments := r.attachmentParts(r.ctx)
+
+	promptToSend := r.buildPrompt(attachments, previous)
+
+	reply := r.generate(promptToSend, append(attachmentParts, r.linkParts(r.ctx)...))
+	if reply.Text != "" {
+		reply.Text = r.filterOutput(reply.Text)
+		reply.Content = withSources(reply.Text, reply.Sources)
+	}
+
+	return reply
+}
+
+// withSources lists the search sources under a reply, shortening the reply
+// rather than the sources when both do not fit in one Discord message. URLs
+// are wrapped in angle brackets so Discord does not embed them.
+func withSources(content string, sources []string) string {
+	if len(sources) == 0 {
+		return content
+	}
+
+	var footer strings.Builder
+	footer.WriteString("\n\n-# Sources:")
+	for idx, source := range sources {
+		footer.WriteString(fmt.Sprintf("\n-# [%v] <%v>", idx+1, source))
 	}
 
+	footerLength := utf8.RuneCountInString(footer.String())
+	if footerLength > maxMessageLength/2 {
+		return content
+	}
+
+	runes := []rune(content)
+	if len(runes)+footerLength > maxMessageLength {
+		content = string(runes[:maxMessageLength-footerLength])
+	}
+
+	return content + footer.String()
+}
+
+// fetchContext loads the conversation history as JSON and the persona for
+// the guild, falling back to empty values when they cannot be fetched.
+func (r *APIRequest) fetchContext() (string, string) {
 	var conversationsString string
 
 	conversations, err := r.Repository.FetchConversations(r.M.GuildID)
 	if err != nil {
-		fmt.Println("Error while fetching conversations:", err)
-		conversationsString = "No conversations stored in history yet."
+		r.logger().Error("Error while fetching conversations", "error", err)
+		conversationsString = "[]"
 	} else {
+		conversations = r.filterConversations(conversations)
+
 		conversationsByte, err := json.Marshal(conversations)
 		if err != nil {
-			fmt.Println("Error while converting conversations:", err)
-			conversationsString = "No conversations stored in history yet."
+			r.logger().Error("Error while converting conversations", "error", err)
+			conversationsString = "[]"
 		} else {
 			conversationsString = string(conversationsByte)
 		}
 	}
 
-	fmt.Println("Conversations:", conversationsString)
+	r.logger().Debug("Fetched conversations", logging.Content("conversations", conversationsString))
 
-	var systemInstructions string
-	fetchedInstructions, err := r.Repository.FetchBotPersona(r.M.GuildID)
+	persona, err := r.Repository.FetchBotPersona(r.M.GuildID)
 	if err != nil {
-		fmt.Println("Error while fetching bot persona:", err)
+		r.logger().Error("Error while fetching bot persona", "error", err)
 	}
-	if fetchedInstructions == "" {
-		systemInstructions = ""
-	} else {
-		systemInstructions = "User defined instructions: '" + fetchedInstructions + "'"
+
+	return conversationsString, persona
+}
+
+// filterConversations drops the conversation being regenerated and the turns
+// of users who opted out, so neither is sent to the model.
+func (r *APIRequest) filterConversations(conversations []structs.Conversation) []structs.Conversation {
+	userIDs := make([]string, 0, len(conversations))
+	for _, conversation := range conversations {
+		if conversation.User.ID != "" {
+			userIDs = append(userIDs, conversation.User.ID)
+		}
 	}
 
-	var sentUser = r.M.Author.DisplayName()
-	fmt.Println("User who sent message:", sentUser)
+	optedOut, err := r.Privacy.FilterOptedOut(userIDs)
+	if err != nil {
+		r.logger().Error("Error while fetching privacy settings", "error", err)
+		return []structs.Conversation{}
+	}
 
-	var sentUserId = r.M.Author.ID
-	fmt.Println("User ID who sent message:", sentUserId)
+	filtered := make([]structs.Conversation, 0, len(conversations))
+	for _, conversation := range conversations {
+		if conversation.ID != "" && conversation.ID == r.ExcludeConversation {
+			continue
+		}
+		if optedOut[conversation.User.ID] {
+			continue
+		}
+		filtered = append(filtered, conversation)
+	}
+
+	return filtered
+}
+
+func (r *APIRequest) generate(promptToSend prompt, extraParts []*genai.Part) Reply {
+	apiKey, err := r.Repository.FetchApiKey(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching API key", "error", err)
+		return failedReply("Could not fetch API key for this server.")
+	}
+	if apiKey == "" {
+		r.logger().Warn("No API key is set")
+		return failedReply("Could not fetch API key for this server.")
+	}
+
+	err = r.checkBudget()
+	if err != nil {
+		r.logger().Info("Returning as usage budget is spent", "reason", err)
+		return failedReply(err.Error())
+	}
+
+	// Everything spent on the guild's key while answering is recorded, also
+	// when answering fails halfway.
+	var usage structs.TokenUsage
+	defer func() {
+		r.recordUsage(usage)
+	}()
 
-	ctx := context.Background()
+	metrics.QueueDepth.Inc()
+	defer metrics.QueueDepth.Dec()
+
+	ctx := r.ctx
 
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
 		APIKey:  apiKey,
@@ -81,115 +269,403 @@ func (r *APIRequest) RequestGenAi() string {
 	})
 
 	if err != nil {
-		fmt.Println("Error creating new Gemini client:", err)
-		return "Error creating new Gemini client."
+		r.logger().Error("Error creating new Gemini client", "error", err)
+		return failedReply("Error creating new Gemini client.")
 	}
 
-	var promptToSend = "Conversation history: '" + conversationsString + "' System message: '" + systemInstructions + "' User '" + sentUser + "' sent the message: '" + r.M.Content + "'"
-	fmt.Println("Sending prompt:", promptToSend)
+	r.logger().Debug("Sending prompt", logging.Content("system", promptToSend.System), logging.Content("prompt", promptToSend.User))
+
+	functionDeclarations := []*genai.FunctionDeclaration{tools.TimeTool, tools.CalculateTool, tools.ConvertTimeTool, tools.TimeDifferenceTool, tools.WeatherTool, tools.SearchTool, tools.FetchURLTool, tools.ReminderTool}
+
+	if r.M.GuildID != "" {
+		functionDeclarations = append(functionDeclarations, tools.DiscordTools...)
+
+		permissions, err := r.Session.UserChannelPermissions(r.M.Author.ID, r.M.ChannelID)
+		if err != nil {
+			r.logger().Error("Error while resolving author permissions", "error", err)
+		}
+		functionDeclarations = append(functionDeclarations, tools.ModerationTools(permissions)...)
+	}
+
+	imageGeneration, err := r.Repository.FetchImageGeneration(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching image generation settings", "error", err)
+	}
+	if imageGeneration.Enabled {
+		functionDeclarations = append(functionDeclarations, tools.ImageTool)
+	}
 
 	config := &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText(promptToSend.System, genai.RoleUser),
 		Tools: []*genai.Tool{
-			{FunctionDeclarations: []*genai.FunctionDeclaration{tools.TimeTool, tools.WeatherTool, tools.SearchTool}},
+			{FunctionDeclarations: functionDeclarations},
 		},
 	}
 
 	contents := []*genai.Content{
 		{
-			Parts: []*genai.Part{{Text: promptToSend}},
+			Parts: append([]*genai.Part{{Text: promptToSend.User}}, extraParts...),
 		},
 	}
 
-	resp, err := client.Models.GenerateContent(
-		ctx,
-		"gemini-2.5-flash-lite",
-		contents,
-		config,
-	)
-	if err != nil {
-		fmt.Println("Error while generating content:", err)
-		return "There was an error while generating your content. If this persists, try deleting your bots conversations or checking your rate limits."
+	state := &toolState{
+		client:          client,
+		imageGeneration: imageGeneration,
+		usage:           &usage,
 	}
 
-	functionCalls := resp.FunctionCalls()
-	if len(functionCalls) > 0 {
+	var response string
+	for round := 0; ; round++ {
+		// Once the round limit is reached the model gets no more tools, so
+		// it has to answer with what it already has.
+		requestConfig := config
+		if round == maxToolRounds {
+			withoutTools := *config
+			withoutTools.Tools = nil
+			requestConfig = &withoutTools
+		}
+
+		start := time.Now()
+		resp, err := client.Models.GenerateContent(
+			ctx,
+			"gemini-2.5-flash-lite",
+			contents,
+			requestConfig,
+		)
+		metrics.ObserveGemini("generate", start, err)
+		if err != nil {
+			r.logger().Error("Error while generating content", "error", err)
+			return failedReply("There was an error while generating your content. If this persists, try clearing your bots conversations with /memory clear or checking your rate limits.")
+		}
+		usage.Add(tokenUsage(resp.UsageMetadata))
+
+		functionCalls := resp.FunctionCalls()
+		if len(functionCalls) == 0 {
+			response = resp.Text()
+			break
+		}
+
+		// The model may call several tools at once. Its turn goes into the
+		// history once, followed by one response for each call, in order.
+		responses := make([]*genai.Part, 0, len(functionCalls))
+
 		for _, fc := range functionCalls {
-			switch fc.Name {
-			case "getTime":
-				time := tools.GetTime(fc.Args["location_iana"].(string)).String()
-
-				contents = append(contents, resp.Candidates[0].Content)
-				contents = append(contents, &genai.Content{
-					Parts: []*genai.Part{
-						genai.NewPartFromFunctionResponse(fc.Name, map[string]any{
-							"time": time,
-						}),
-					},
-				})
-			case "getWeather":
-				apiKey, err := r.Repository.FetchWeatherApiKey(r.M.GuildID)
-				if err != nil {
-					fmt.Println("Error while fetching weather API key:", err)
-					return "There was an error while fetching the weather. Please check whether your API key is valid and your rate limits."
-				}
-
-				weather, err := tools.GetWeather(apiKey, fc.Args["location"].(string))
-				if err != nil {
-					fmt.Println("Error while fetching weather data:", err)
-					return "There was an error while fetching the weather. Please check whether your API key is valid and your rate limits."
-				}
-
-				contents = append(contents, resp.Candidates[0].Content)
-				contents = append(contents, &genai.Content{
-					Parts: []*genai.Part{
-						genai.NewPartFromFunctionResponse(fc.Name, map[string]any{
-							"weather": weather,
-						}),
-					},
-				})
-			case "vyntrSearch":
-				apiKey, err := r.Repository.FetchVyntrApiKey(r.M.GuildID)
-				if err != nil {
-					fmt.Println("Error while fetching Vyntr API key:", err)
-					return "There was an error while searching with Vyntr. Please check whether your API key is valid and your rate limits."
-				}
-
-				results, err := tools.VyntrSearch(apiKey, fc.Args["query"].(string))
-				if err != nil {
-					fmt.Println("Error while searching with Vyntr:", err)
-					return "There was an error while searching with Vyntr. Please check whether your API key is valid and your rate limits."
-				}
-
-				contents = append(contents, resp.Candidates[0].Content)
-				contents = append(contents, &genai.Content{
-					Parts: []*genai.Part{
-						genai.NewPartFromFunctionResponse(fc.Name, map[string]any{
-							"results": results,
-						}),
-					},
-				})
-			default:
-				fmt.Println("Unsupported tool.")
-				return "Unsupported tool called. Please try again."
+			result, err := r.callTool(ctx, state, fc)
+			if err != nil {
+				return failedReply(err.Error())
 			}
+
+			responses = append(responses, genai.NewPartFromFunctionResponse(fc.Name, result))
+		}
+
+		contents = append(contents, resp.Candidates[0].Content)
+		contents = append(contents, &genai.Content{
+			Role:  genai.RoleUser,
+			Parts: responses,
+		})
+	}
+
+	if response == "" {
+		return failedReply("The model returned an empty response. Please try again.")
+	}
+
+	health.RecordGeneration()
+
+	return Reply{
+		Content: response,
+		Text:    response,
+		Files:   state.files,
+		Sources: state.sources,
+		Actions: state.actions,
+	}
+}
+
+// toolState collects what the tools produce while answering one message.
+type toolState struct {
+	client          *genai.Client
+	imageGeneration structs.ImageGeneration
+	usage           *structs.TokenUsage
+
+	files   []*discordgo.File
+	sources []string
+	actions []structs.ModerationAction
+}
+
+// callTool runs one function call and returns the response for the model.
+// An error means the reply cannot be generated at all and is shown to the
+// user instead.
+func (r *APIRequest) callTool(ctx context.Context, state *toolState, fc *genai.FunctionCall) (map[string]any, error) {
+	r.logger().Info("Calling tool", "tool", fc.Name)
+
+	switch fc.Name {
+	case "getTime", "convertTime", "timeDifference", "calculate", "getServerInfo", "getMember", "listRoles", "getChannelTopic":
+		var result map[string]any
+		var err error
+
+		switch fc.Name {
+		case "getTime":
+			result, err = tools.GetTime(stringArg(fc.Args, "location_iana"))
+		case "convertTime":
+			result, err = tools.ConvertTime(stringArg(fc.Args, "time"), stringArg(fc.Args, "from"), stringArg(fc.Args, "to"))
+		case "timeDifference":
+			result, err = tools.TimeDifference(stringArg(fc.Args, "start"), stringArg(fc.Args, "end"), stringArg(fc.Args, "location"))
+		case "calculate":
+			result, err = tools.Calculate(stringArg(fc.Args, "expression"))
+		case "getServerInfo":
+			result, err = tools.GetServerInfo(r.discordContext())
+		case "getMember":
+			result, err = tools.GetMember(r.discordContext(), stringArg(fc.Args, "member"))
+		case "listRoles":
+			moderatorsOnly, _ := fc.Args["moderators_only"].(bool)
+			result, err = tools.ListRoles(r.discordContext(), moderatorsOnly)
+		case "getChannelTopic":
+			result, err = tools.GetChannelTopic(r.discordContext(), stringArg(fc.Args, "channel"))
+		}
+
+		// Errors go back to the model so it can correct the input or
+		// ask, instead of answering with a wrong result.
+		if err != nil {
+			r.logger().Info("Tool returned an error", "tool", fc.Name, "error", err)
+			result = map[string]any{"error": err.Error()}
 		}
+		metrics.RecordToolCall(fc.Name, err != nil)
+
+		return result, nil
+	case "getWeather":
+		// Failures go back to the model, which can ask for a clearer
+		// location or explain that the weather is unavailable.
+		apiKey, err := r.Repository.FetchWeatherApiKey(r.M.GuildID)
+		if err != nil {
+			r.logger().Error("Error while fetching weather API key", "error", err)
+			metrics.RecordToolCall(fc.Name, true)
+			return map[string]any{"error": "The weather is not available: no valid OpenWeatherMap API key is set for this server."}, nil
+		}
+
+		units := stringArg(fc.Args, "units")
+		days, _ := fc.Args["days"].(float64)
+
+		weather, err := tools.GetWeather(ctx, apiKey, stringArg(fc.Args, "location"), units, int(days))
+		if err != nil {
+			r.logger().Info("Error while fetching weather data", "error", err)
+			metrics.RecordToolCall(fc.Name, true)
+			return map[string]any{"error": err.Error()}, nil
+		}
+
+		metrics.RecordToolCall(fc.Name, false)
+
+		return map[string]any{"weather": weather}, nil
+	case "webSearch":
+		// A provider that is not set up or fails is reported to the model,
+		// which can still answer without the search.
+		provider, err := r.searchProvider(state.client)
+		if err != nil {
+			r.logger().Warn("Error while setting up search provider", "error", err)
+			metrics.RecordToolCall(fc.Name, true)
+			return map[string]any{"error": fmt.Sprintf("Web search is not available: %v", err)}, nil
+		}
+
+		results, err := provider.Search(ctx, stringArg(fc.Args, "query"))
+		if google, ok := provider.(*search.Google); ok {
+			state.usage.Add(tokenUsage(google.Usage))
+		}
+		if err != nil {
+			r.logger().Error("Error while searching", "provider", provider.Name(), "error", err)
+			metrics.RecordToolCall(fc.Name, true)
+			return map[string]any{"error": fmt.Sprintf("Searching with %v failed. The API key may be invalid or the rate limit reached.", provider.Name())}, nil
+		}
+
+		// Results are numbered across every search in this reply so the
+		// model's citations match the sources listed under it.
+		numbered := make([]map[string]any, 0, len(results))
+		for _, result := range results {
+			state.sources = append(state.sources, result.URL)
+			numbered = append(numbered, map[string]any{
+				"number":  len(state.sources),
+				"title":   result.Title,
+				"url":     result.URL,
+				"snippet": result.Snippet,
+			})
+		}
+
+		metrics.RecordToolCall(fc.Name, false)
+
+		return map[string]any{"results": numbered}, nil
+	case "fetchUrl":
+		result := fetchPage(ctx, stringArg(fc.Args, "url"))
+		metrics.RecordToolCall(fc.Name, failedResult(result))
+
+		return result, nil
+	case "createReminder":
+		result := r.createReminder(fc.Args)
+		metrics.RecordToolCall(fc.Name, failedResult(result))
+
+		return result, nil
+	case "timeoutMember", "deleteMessages", "addRole":
+		action, result := r.proposeModeration(fc.Name, fc.Args)
+		if action != nil {
+			state.actions = append(state.actions, *action)
+		}
+		metrics.RecordToolCall(fc.Name, failedResult(result))
+
+		return result, nil
+	case "generateImage":
+		file, result := r.generateImage(ctx, state.client, state.imageGeneration, stringArg(fc.Args, "prompt"), len(state.files), state.usage)
+		if file != nil {
+			state.files = append(state.files, file)
+		}
+		metrics.RecordToolCall(fc.Name, failedResult(result))
+
+		return result, nil
 	}
 
-	finalResp, err := client.Models.GenerateContent(ctx, "gemini-2.5-flash-lite", contents, config)
+	r.logger().Warn("Unsupported tool called", "tool", fc.Name)
+	metrics.RecordToolCall(fc.Name, true)
+
+	return nil, fmt.Errorf("Unsupported tool called. Please try again.")
+}
+
+// discordContext scopes the Discord tools to the author of the message.
+func (r *APIRequest) discordContext() tools.DiscordContext {
+	return tools.DiscordContext{
+		Ctx:       r.ctx,
+		Session:   r.Session,
+		GuildID:   r.M.GuildID,
+		ChannelID: r.M.ChannelID,
+		UserID:    r.M.Author.ID,
+	}
+}
+
+// createReminder schedules a reminder for the author in the current channel.
+// Like the time tools, problems are reported back to the model so it can ask
+// the user to clarify.
+func (r *APIRequest) createReminder(args map[string]any) map[string]any {
+	message := strings.TrimSpace(stringArg(args, "message"))
+	if message == "" {
+		return map[string]any{"error": "The reminder needs a message."}
+	}
+
+	dueAt, err := tools.ReminderTime(stringArg(args, "in"), stringArg(args, "at"), stringArg(args, "location"))
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	reminder, err := r.Reminders.Create(structs.Reminder{
+		GuildID:   r.M.GuildID,
+		ChannelID: r.M.ChannelID,
+		UserID:    r.M.Author.ID,
+		Message:   message,
+		DueAt:     dueAt,
+	})
+	if err != nil {
+		r.logger().Error("Error while creating reminder", "error", err)
+		return map[string]any{"error": err.Error()}
+	}
+
+	return map[string]any{
+		"id":      reminder.ID.Hex(),
+		"message": reminder.Message,
+		"due_at":  reminder.DueAt.Format(time.RFC3339),
+		"discord": fmt.Sprintf("<t:%v:F>", reminder.DueAt.Unix()),
+	}
+}
+
+// proposeModeration stores a moderation action for the author to confirm.
+// The model is told that it is waiting for confirmation, or why it cannot be
+// proposed.
+func (r *APIRequest) proposeModeration(name string, args map[string]any) (*structs.ModerationAction, map[string]any) {
+	action, err := tools.ProposeModeration(r.discordContext(), name, args)
+	if err != nil {
+		return nil, map[string]any{"error": err.Error()}
+	}
+
+	now := time.Now()
+	action.RequestID = r.M.ID
+	action.CreatedAt = now
+	action.ExpiresAt = now.Add(moderation.ConfirmationTimeout)
+
+	action, err = r.Moderation.Create(action)
+	if err != nil {
+		r.logger().Error("Error while storing moderation action", "error", err)
+		return nil, map[string]any{"error": err.Error()}
+	}
+
+	return &action, map[string]any{
+		"status": "Waiting for confirmation. Tell the user to press the Confirm button below your reply; nothing has happened yet.",
+		"action": moderation.Describe(action),
+	}
+}
+
+// searchProvider sets up the web search provider the guild chose, with its
+// API key where the provider needs one.
+func (r *APIRequest) searchProvider(client *genai.Client) (search.Provider, error) {
+	name, err := r.Repository.FetchSearchProvider(r.M.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the search settings: %w", err)
+	}
+	if name == "" {
+		name = search.DefaultProvider
+	}
+
+	switch name {
+	case search.VyntrProvider:
+		apiKey, err := r.Repository.FetchVyntrApiKey(r.M.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		return search.NewVyntr(apiKey), nil
+	case search.SearXNGProvider:
+		return search.NewSearXNG()
+	case search.BraveProvider:
+		apiKey, err := r.Repository.FetchBraveApiKey(r.M.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		return search.NewBrave(apiKey), nil
+	case search.GoogleProvider:
+		return search.NewGoogle(client, "gemini-2.5-flash-lite"), nil
+	}
+
+	return nil, fmt.Errorf("unknown search provider '%v'", name)
+}
+
+// generateImage runs the image tool within the guild's daily quota. The
+// result tells the model what happened, and the file is nil unless an image
+// was generated.
+func (r *APIRequest) generateImage(ctx context.Context, client *genai.Client, settings structs.ImageGeneration, prompt string, index int, usage *structs.TokenUsage) (*discordgo.File, map[string]any) {
+	if !settings.Enabled {
+		return nil, map[string]any{"error": "Image generation is disabled in this server."}
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return nil, map[string]any{"error": "No prompt was given for the image."}
+	}
+
+	reserved, err := r.Quota.Reserve(r.M.GuildID, "generateImage", settings.DailyLimit)
 	if err != nil {
-		fmt.Println("Error while generating content:", err)
-		return "There was an error while generating your content. If this persists, try deleting your bots conversations or checking your rate limits."
+		r.logger().Error("Error while reserving image quota", "error", err)
+		return nil, map[string]any{"error": "Could not check the daily image limit."}
+	}
+	if !reserved {
+		return nil, map[string]any{"error": fmt.Sprintf("This server has used its daily limit of %v images.", settings.DailyLimit)}
 	}
 
-	var messageSent structs.User
-	messageSent.Name = r.M.Author.DisplayName()
-	messageSent.Message = r.M.Content
+	image, metadata, err := tools.GenerateImage(ctx, client, prompt)
+	usage.Add(tokenUsage(metadata))
+	if err != nil {
+		r.logger().Error("Error while generating image", "error", err)
+		r.Quota.Release(r.M.GuildID, "generateImage")
+		return nil, map[string]any{"error": "The image could not be generated."}
+	}
 
-	var response string = finalResp.Text()
+	extension, ok := imagefetch.Extension(image.MIMEType)
+	if !ok {
+		extension = ".png"
+	}
 
-	if response != "" {
-		r.Repository.AddConversations(r.M.GuildID, messageSent, response)
+	file := &discordgo.File{
+		Name:        fmt.Sprintf("generated_%v%v", index, extension),
+		ContentType: image.MIMEType,
+		Reader:      bytes.NewReader(image.Data),
 	}
 
-	return "<@" + sentUserId + "> " + response
+	return file, map[string]any{"status": "The image was generated and will be attached to your reply."}
 }
@@ -0,0 +1,191 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+	"google.golang.org/genai"
+)
+
+const (
+	maxAttachments         = 6
+	maxAttachmentBytes     = 10 << 20
+	maxAttachmentsTotal    = 18 << 20
+	attachmentFetchTimeout = 20 * time.Second
+)
+
+// supportedAttachmentTypes lists the content types Gemini accepts as inline
+// data.
+var supportedAttachmentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/webp":      true,
+	"image/heic":      true,
+	"image/heif":      true,
+	"audio/wav":       true,
+	"audio/mpeg":      true,
+	"audio/mp3":       true,
+	"audio/aac":       true,
+	"audio/ogg":       true,
+	"audio/flac":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
+
+var attachmentClient = &http.Client{Timeout: attachmentFetchTimeout}
+
+type pendingAttachment struct {
+	URL  string
+	Meta structs.Attachment
+}
+
+// pendingAttachments lists the files of a message that can be sent to the
+// model: attachments, image embeds and non-animated stickers.
+func pendingAttachments(m *discordgo.Message, source string) []pendingAttachment {
+	pending := []pendingAttachment{}
+
+	for _, attachment := range m.Attachments {
+		pending = append(pending, pendingAttachment{
+			URL: attachment.URL,
+			Meta: structs.Attachment{
+				Name:        attachment.Filename,
+				ContentType: attachment.ContentType,
+				Size:        attachment.Size,
+				Source:      source,
+			},
+		})
+	}
+
+	for _, embed := range m.Embeds {
+		if embed.Image != nil && embed.Image.ProxyURL != "" {
+			pending = append(pending, pendingAttachment{
+				URL:  embed.Image.ProxyURL,
+				Meta: structs.Attachment{Name: "embed image", Source: source},
+			})
+		} else if embed.Thumbnail != nil && embed.Thumbnail.ProxyURL != "" {
+			pending = append(pending, pendingAttachment{
+				URL:  embed.Thumbnail.ProxyURL,
+				Meta: structs.Attachment{Name: "embed thumbnail", Source: source},
+			})
+		}
+	}
+
+	for _, sticker := range m.StickerItems {
+		if sticker.FormatType != discordgo.StickerFormatTypePNG && sticker.FormatType != discordgo.StickerFormatTypeAPNG {
+			continue
+		}
+
+		pending = append(pending, pendingAttachment{
+			URL: "https://media.discordapp.net/stickers/" + sticker.ID + ".png",
+			Meta: structs.Attachment{
+				Name:        "sticker " + sticker.Name,
+				ContentType: "image/png",
+				Source:      source,
+			},
+		})
+	}
+
+	return pending
+}
+
+// attachmentParts downloads the files on the triggering message and on the
+// message it replies to, within the size and type limits, and returns them as
+// inline data along with the metadata of what was included. Files that fail
+// or are not supported are skipped.
+func (r *APIRequest) attachmentParts(ctx context.Context) ([]*genai.Part, []structs.Attachment) {
+	pending := pendingAttachments(r.M.Message, "message")
+
+	if referenced := r.M.ReferencedMessage; referenced != nil && referenced.Author != nil {
+		optedOut, err := r.Privacy.IsOptedOut(referenced.Author.ID)
+		if err != nil {
+			r.logger().Error("Error while checking privacy of replied-to author", "error", err)
+		} else if !optedOut {
+			pending = append(pending, pendingAttachments(referenced, "reply")...)
+		}
+	}
+
+	parts := []*genai.Part{}
+	included := []structs.Attachment{}
+	var total int
+
+	for _, attachment := range pending {
+		if len(included) == maxAttachments {
+			r.logger().Info("Attachment limit reached, skipping the rest")
+			break
+		}
+
+		data, contentType, err := downloadAttachment(ctx, attachment.URL, attachment.Meta.ContentType)
+		if err != nil {
+			r.logger().Info("Skipping attachment", "name", attachment.Meta.Name, "error", err)
+			continue
+		}
+
+		if total+len(data) > maxAttachmentsTotal {
+			r.logger().Info("Skipping attachment over the total size limit", "name", attachment.Meta.Name)
+			continue
+		}
+		total += len(data)
+
+		meta := attachment.Meta
+		meta.ContentType = contentType
+		meta.Size = len(data)
+
+		parts = append(parts, genai.NewPartFromBytes(data, contentType))
+		included = append(included, meta)
+	}
+
+	return parts, included
+}
+
+func downloadAttachment(ctx context.Context, url string, declaredType string) ([]byte, string, error) {
+	contentType, _, _ := mime.ParseMediaType(declaredType)
+	if contentType != "" && !supportedAttachmentTypes[contentType] {
+		return nil, "", fmt.Errorf("unsupported content type '%v'", contentType)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := attachmentClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download: status %v", resp.StatusCode)
+	}
+
+	if resp.ContentLength > maxAttachmentBytes {
+		return nil, "", fmt.Errorf("too large (%v bytes)", resp.ContentLength)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read: %v", err)
+	}
+	if len(data) > maxAttachmentBytes {
+		return nil, "", fmt.Errorf("larger than %v bytes", maxAttachmentBytes)
+	}
+
+	if contentType == "" {
+		contentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	}
+	if !supportedAttachmentTypes[contentType] {
+		contentType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+	if !supportedAttachmentTypes[contentType] {
+		return nil, "", fmt.Errorf("unsupported content type '%v'", contentType)
+	}
+
+	return data, contentType, nil
+}
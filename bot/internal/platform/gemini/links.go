@@ -0,0 +1,105 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"bot/internal/logging"
+	"bot/internal/webfetch"
+
+	"google.golang.org/genai"
+)
+
+const (
+	maxPrefetchedLinks = 3
+	maxPrefetchedText  = 4000
+)
+
+// pageFetcher serves the fetchUrl tool, and prefetchFetcher reads links in
+// the message up front with a smaller excerpt, since several may be sent.
+var (
+	pageFetcher     = webfetch.NewFetcher()
+	prefetchFetcher = func() *webfetch.Fetcher {
+		fetcher := webfetch.NewFetcher()
+		fetcher.MaxText = maxPrefetchedText
+		return fetcher
+	}()
+)
+
+// fetchPage runs the fetchUrl tool. Failures are reported to the model so it
+// can tell the user the page could not be read.
+func fetchPage(ctx context.Context, url string) map[string]any {
+	page, err := pageFetcher.Fetch(ctx, url)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Error while fetching page", "url", url, "error", err)
+		return map[string]any{"error": err.Error()}
+	}
+
+	return map[string]any{
+		"url":       page.URL,
+		"title":     page.Title,
+		"text":      page.Text,
+		"truncated": page.Truncated,
+	}
+}
+
+// linkParts reads the links in the message when the guild turned on link
+// prefetching, so the model can answer questions about them without calling
+// fetchUrl. Links that cannot be read are left to the model.
+func (r *APIRequest) linkParts(ctx context.Context) []*genai.Part {
+	enabled, err := r.Repository.FetchPrefetchLinks(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching link settings", "error", err)
+		return nil
+	}
+	if !enabled {
+		return nil
+	}
+
+	links := webfetch.Links(r.M.Content, maxPrefetchedLinks)
+	pages := make([]*webfetch.Page, len(links))
+
+	var wg sync.WaitGroup
+	for idx, link := range links {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			page, err := prefetchFetcher.Fetch(ctx, link)
+			if err != nil {
+				r.logger().Warn("Error while prefetching link", "url", link, "error", err)
+				return
+			}
+			pages[idx] = &page
+		}()
+	}
+	wg.Wait()
+
+	var parts []*genai.Part
+	for _, page := range pages {
+		if page == nil {
+			continue
+		}
+
+		pageBytes, err := json.Marshal(promptPage{
+			URL:       page.URL,
+			Title:     page.Title,
+			Text:      page.Text,
+			Truncated: page.Truncated,
+		})
+		if err != nil {
+			r.logger().Error("Error while converting page", "error", err)
+			continue
+		}
+
+		text := "Page linked in the message:\n" + string(pageBytes)
+		if page.Truncated {
+			text += "\nThe page continues, use fetchUrl for more."
+		}
+		parts = append(parts, genai.NewPartFromText(text))
+	}
+
+	return parts
+}
@@ -0,0 +1,102 @@
+package gemini
+
+import (
+	"context"
+	"fmt"
+
+	"bot/internal/screening"
+	"bot/internal/structs"
+
+	"google.golang.org/genai"
+)
+
+// ScreenInput runs the message through the checks the guild enabled before
+// it is sent to the model.
+func (r *APIRequest) ScreenInput(settings structs.InputScreening) screening.Verdict {
+	ctx := r.ctx
+
+	pipeline := r.screeningPipeline(ctx, settings)
+	verdict := pipeline.Screen(ctx, r.M.Content)
+
+	for _, stage := range pipeline.Stages {
+		if classifier, ok := stage.(*screening.SafetyClassifier); ok && classifier.Usage != nil {
+			r.recordUsage(tokenUsage(classifier.Usage))
+		}
+	}
+
+	return verdict
+}
+
+// screeningPipeline orders the stages from cheapest to most expensive, so
+// the classifier is only called for messages the filters let through.
+func (r *APIRequest) screeningPipeline(ctx context.Context, settings structs.InputScreening) screening.Pipeline {
+	var pipeline screening.Pipeline
+
+	if len(settings.BlockedWords) > 0 {
+		pipeline.Stages = append(pipeline.Stages, screening.NewBlocklist(settings.BlockedWords))
+	}
+
+	if len(settings.BlockedPatterns) > 0 {
+		patterns, err := screening.NewPatterns("blocked patterns", settings.BlockedPatterns)
+		if err != nil {
+			r.logger().Error("Error while compiling blocked patterns", "error", err)
+		} else {
+			pipeline.Stages = append(pipeline.Stages, patterns)
+		}
+	}
+
+	if settings.InjectionFilter {
+		pipeline.Stages = append(pipeline.Stages, screening.NewInjectionFilter())
+	}
+
+	if settings.SafetyThreshold != "" {
+		client, err := r.safetyClient(ctx)
+		if err != nil {
+			r.logger().Error("Error while setting up safety classifier", "error", err)
+		} else {
+			pipeline.Stages = append(pipeline.Stages, &screening.SafetyClassifier{Client: client, Threshold: settings.SafetyThreshold})
+		}
+	}
+
+	return pipeline
+}
+
+func (r *APIRequest) safetyClient(ctx context.Context) (*genai.Client, error) {
+	apiKey, err := r.Repository.FetchApiKey(r.M.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("no API key is set")
+	}
+
+	return genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  apiKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+}
+
+// filterOutput cleans up the model's answer with the guild's output filter.
+// If the settings cannot be fetched, the defaults still apply.
+func (r *APIRequest) filterOutput(text string) string {
+	settings, err := r.Repository.FetchOutputFilter(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching output filter", "error", err)
+	}
+
+	inputScreening, err := r.Repository.FetchInputScreening(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching screening settings", "error", err)
+	}
+
+	outputFilter := screening.OutputFilter{
+		Invites:      !settings.AllowInvites,
+		MassMentions: !settings.AllowMassMentions,
+		Mode:         settings.Mode,
+	}
+	if len(inputScreening.BlockedWords) > 0 {
+		outputFilter.Blocklist = screening.NewBlocklist(inputScreening.BlockedWords)
+	}
+
+	return outputFilter.Apply(text)
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"bot/internal/calc"
+
+	"google.golang.org/genai"
+)
+
+var CalculateTool = &genai.FunctionDeclaration{
+	Name:        "calculate",
+	Description: "Evaluates math exactly, including big integers, functions such as sqrt, sin, log, round and factorials, and unit conversions of length, mass, temperature, data size, volume, time and speed. Always use this instead of doing arithmetic yourself",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"expression": {
+				Type:        genai.TypeString,
+				Description: "Expression such as '2^100 / 3', 'sqrt(2) * 5!' or a conversion such as '5 km to mi', '100 °F to °C' or '1 GiB to MB'",
+			},
+		},
+		Required: []string{"expression"},
+	},
+}
+
+func Calculate(expression string) (map[string]any, error) {
+	result, err := calc.Evaluate(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"expression": result.Expression,
+		"result":     result.String(),
+		"exact":      result.Exact,
+	}, nil
+}
@@ -0,0 +1,443 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+	"google.golang.org/genai"
+)
+
+var ServerInfoTool = &genai.FunctionDeclaration{
+	Name:        "getServerInfo",
+	Description: "Gets details about this Discord server, such as its owner, creation date, member count and boost level",
+	Parameters: &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: map[string]*genai.Schema{},
+	},
+}
+
+var MemberTool = &genai.FunctionDeclaration{
+	Name:        "getMember",
+	Description: "Looks up a member of this Discord server, including when they joined and their roles",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"member": {
+				Type:        genai.TypeString,
+				Description: "Mention such as <@123>, user ID, or name. Use 'me' for the user who sent the message",
+			},
+		},
+		Required: []string{"member"},
+	},
+}
+
+var RolesTool = &genai.FunctionDeclaration{
+	Name:        "listRoles",
+	Description: "Lists the roles of this Discord server, marking the ones with moderation permissions. Can list who has the moderator roles, for questions such as 'who are the mods here?'",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"moderators_only": {
+				Type:        genai.TypeBoolean,
+				Description: "Only list roles with moderation permissions, along with their members",
+			},
+		},
+	},
+}
+
+var ChannelTool = &genai.FunctionDeclaration{
+	Name:        "getChannelTopic",
+	Description: "Gets the topic and details of a channel in this Discord server, the current channel by default",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"channel": {
+				Type:        genai.TypeString,
+				Description: "Mention such as <#123>, channel ID or name. Leave empty for the current channel",
+			},
+		},
+	},
+}
+
+// DiscordTools are offered in servers only.
+var DiscordTools = []*genai.FunctionDeclaration{ServerInfoTool, MemberTool, RolesTool, ChannelTool}
+
+// moderationPermissions mark a role as one held by moderators.
+const moderationPermissions = discordgo.PermissionAdministrator | discordgo.PermissionManageGuild |
+	discordgo.PermissionManageMessages | discordgo.PermissionModerateMembers |
+	discordgo.PermissionKickMembers | discordgo.PermissionBanMembers
+
+// maxListedMembers caps the members listed per role.
+const maxListedMembers = 25
+
+var (
+	userMentionPattern    = regexp.MustCompile(`^<@!?(\d+)>$`)
+	channelMentionPattern = regexp.MustCompile(`^<#(\d+)>$`)
+	snowflakePattern      = regexp.MustCompile(`^\d{15,21}$`)
+)
+
+// DiscordContext is who is asking and where. The tools only return what
+// that user could see in Discord themselves.
+type DiscordContext struct {
+	Ctx       context.Context
+	Session   *discordgo.Session
+	GuildID   string
+	ChannelID string
+	UserID    string
+}
+
+func (d DiscordContext) logger() *slog.Logger {
+	return logging.FromContext(d.Ctx)
+}
+
+// MembersIntent reports whether the bot receives the privileged member
+// events, without which member lists are unavailable.
+func (d DiscordContext) MembersIntent() bool {
+	return d.Session.Identify.Intents&discordgo.IntentsGuildMembers != 0
+}
+
+func (d DiscordContext) guild() (*discordgo.Guild, error) {
+	guild, err := d.Session.State.Guild(d.GuildID)
+	if err == nil {
+		return guild, nil
+	}
+
+	guild, err = d.Session.Guild(d.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the server: %v", err)
+	}
+
+	return guild, nil
+}
+
+func (d DiscordContext) canView(channelID string) bool {
+	permissions, err := d.Session.UserChannelPermissions(d.UserID, channelID)
+	if err != nil {
+		d.logger().Error("Error while resolving channel permissions", "error", err)
+		return false
+	}
+
+	return permissions&discordgo.PermissionViewChannel != 0
+}
+
+func (d DiscordContext) channels(guild *discordgo.Guild) []*discordgo.Channel {
+	if len(guild.Channels) > 0 {
+		return guild.Channels
+	}
+
+	channels, err := d.Session.GuildChannels(d.GuildID)
+	if err != nil {
+		d.logger().Error("Error while fetching channels", "error", err)
+	}
+
+	return channels
+}
+
+func (d DiscordContext) roles(guild *discordgo.Guild) []*discordgo.Role {
+	if len(guild.Roles) > 0 {
+		return guild.Roles
+	}
+
+	roles, err := d.Session.GuildRoles(d.GuildID)
+	if err != nil {
+		d.logger().Error("Error while fetching roles", "error", err)
+	}
+
+	return roles
+}
+
+func discordTime(t time.Time) map[string]any {
+	return map[string]any{
+		"utc":     t.UTC().Format(time.RFC3339),
+		"discord": fmt.Sprintf("<t:%v:D>", t.Unix()),
+	}
+}
+
+func GetServerInfo(d DiscordContext) (map[string]any, error) {
+	guild, err := d.guild()
+	if err != nil {
+		return nil, err
+	}
+
+	// The state does not keep the approximate counts, so they come from the
+	// API.
+	counted, err := d.Session.GuildWithCounts(d.GuildID)
+	if err != nil {
+		d.logger().Error("Error while fetching guild counts", "error", err)
+		counted = guild
+	}
+
+	created, _ := discordgo.SnowflakeTimestamp(guild.ID)
+
+	visibleChannels := 0
+	for _, channel := range d.channels(guild) {
+		if channel.Type != discordgo.ChannelTypeGuildCategory && d.canView(channel.ID) {
+			visibleChannels++
+		}
+	}
+
+	info := map[string]any{
+		"name":               guild.Name,
+		"description":        guild.Description,
+		"owner":              "<@" + guild.OwnerID + ">",
+		"created":            discordTime(created),
+		"visible_channels":   visibleChannels,
+		"roles":              len(d.roles(guild)) - 1,
+		"boost_level":        int(guild.PremiumTier),
+		"boosts":             guild.PremiumSubscriptionCount,
+		"preferred_language": guild.PreferredLocale,
+	}
+
+	if counted.ApproximateMemberCount > 0 {
+		info["members"] = counted.ApproximateMemberCount
+		info["members_online"] = counted.ApproximatePresenceCount
+	} else if guild.MemberCount > 0 {
+		info["members"] = guild.MemberCount
+	}
+
+	return info, nil
+}
+
+func GetMember(d DiscordContext, query string) (map[string]any, error) {
+	member, err := d.findMember(strings.TrimSpace(query))
+	if err != nil {
+		return nil, err
+	}
+
+	guild, err := d.guild()
+	if err != nil {
+		return nil, err
+	}
+
+	roleNames := make(map[string]string)
+	for _, role := range d.roles(guild) {
+		roleNames[role.ID] = role.Name
+	}
+
+	roles := make([]string, 0, len(member.Roles))
+	for _, roleID := range member.Roles {
+		if name, ok := roleNames[roleID]; ok {
+			roles = append(roles, name)
+		}
+	}
+
+	info := map[string]any{
+		"mention":      "<@" + member.User.ID + ">",
+		"display_name": member.DisplayName(),
+		"username":     member.User.Username,
+		"bot":          member.User.Bot,
+		"roles":        roles,
+		"owner":        member.User.ID == guild.OwnerID,
+	}
+
+	if !member.JoinedAt.IsZero() {
+		info["joined_server"] = discordTime(member.JoinedAt)
+	}
+	if created, err := discordgo.SnowflakeTimestamp(member.User.ID); err == nil {
+		info["account_created"] = discordTime(created)
+	}
+	if member.PremiumSince != nil {
+		info["boosting_since"] = discordTime(*member.PremiumSince)
+	}
+	if member.CommunicationDisabledUntil != nil && member.CommunicationDisabledUntil.After(time.Now()) {
+		info["timed_out_until"] = discordTime(*member.CommunicationDisabledUntil)
+	}
+
+	return info, nil
+}
+
+// findMember resolves a mention, ID or name to a member. Names are searched
+// through the API, which works without the members intent.
+func (d DiscordContext) findMember(query string) (*discordgo.Member, error) {
+	if query == "" || strings.EqualFold(query, "me") {
+		query = d.UserID
+	}
+	if match := userMentionPattern.FindStringSubmatch(query); match != nil {
+		query = match[1]
+	}
+
+	if snowflakePattern.MatchString(query) {
+		if member, err := d.Session.State.Member(d.GuildID, query); err == nil && !member.JoinedAt.IsZero() {
+			return member, nil
+		}
+
+		member, err := d.Session.GuildMember(d.GuildID, query)
+		if err != nil {
+			return nil, fmt.Errorf("no member with the ID %v is in this server", query)
+		}
+		return member, nil
+	}
+
+	query = strings.TrimPrefix(query, "@")
+
+	members, err := d.Session.GuildMembersSearch(d.GuildID, query, 5)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for members: %v", err)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no member named '%v' was found", query)
+	}
+
+	for _, member := range members {
+		if strings.EqualFold(member.DisplayName(), query) || strings.EqualFold(member.User.Username, query) {
+			return member, nil
+		}
+	}
+
+	return members[0], nil
+}
+
+func ListRoles(d DiscordContext, moderatorsOnly bool) (map[string]any, error) {
+	guild, err := d.guild()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := slices.Clone(d.roles(guild))
+	slices.SortFunc(roles, func(a, b *discordgo.Role) int {
+		return b.Position - a.Position
+	})
+
+	// Member lists come from the state, which only has every member when
+	// the members intent is enabled. The state updates them from gateway
+	// events while we read, so they are only read under its lock.
+	var membersByRole map[string][]string
+	if d.MembersIntent() {
+		membersByRole = make(map[string][]string)
+
+		d.Session.State.RLock()
+		for _, member := range guild.Members {
+			for _, roleID := range member.Roles {
+				membersByRole[roleID] = append(membersByRole[roleID], member.DisplayName())
+			}
+		}
+		d.Session.State.RUnlock()
+	}
+
+	listed := make([]map[string]any, 0, len(roles))
+	for _, role := range roles {
+		if role.ID == guild.ID {
+			continue
+		}
+
+		moderator := role.Permissions&moderationPermissions != 0
+		if moderatorsOnly && !moderator {
+			continue
+		}
+
+		entry := map[string]any{
+			"name":        role.Name,
+			"mention":     "<@&" + role.ID + ">",
+			"moderator":   moderator,
+			"shown_apart": role.Hoist,
+			"integration": role.Managed,
+		}
+		if role.Color != 0 {
+			entry["color"] = fmt.Sprintf("#%06x", role.Color)
+		}
+
+		if membersByRole != nil {
+			members := membersByRole[role.ID]
+			entry["member_count"] = len(members)
+			if moderatorsOnly {
+				entry["members"] = members[:min(len(members), maxListedMembers)]
+			}
+		}
+
+		listed = append(listed, entry)
+	}
+
+	result := map[string]any{"roles": listed}
+	if membersByRole == nil {
+		result["note"] = "Member lists are not available because the bot does not have the server members intent."
+	}
+
+	return result, nil
+}
+
+func GetChannelTopic(d DiscordContext, query string) (map[string]any, error) {
+	guild, err := d.guild()
+	if err != nil {
+		return nil, err
+	}
+
+	channels := d.channels(guild)
+	channel := d.findChannel(channels, strings.TrimSpace(query))
+
+	// Channels the user cannot see are reported as missing, so their names
+	// are not confirmed either.
+	if channel == nil || !d.canView(channel.ID) {
+		return nil, fmt.Errorf("no channel '%v' that you can see was found", query)
+	}
+
+	info := map[string]any{
+		"name":    channel.Name,
+		"mention": "<#" + channel.ID + ">",
+		"topic":   channel.Topic,
+		"nsfw":    channel.NSFW,
+	}
+
+	if created, err := discordgo.SnowflakeTimestamp(channel.ID); err == nil {
+		info["created"] = discordTime(created)
+	}
+	if channel.RateLimitPerUser > 0 {
+		info["slowmode_seconds"] = channel.RateLimitPerUser
+	}
+
+	for _, parent := range channels {
+		if parent.ID == channel.ParentID {
+			info["category"] = parent.Name
+		}
+	}
+
+	switch channel.Type {
+	case discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice:
+		info["type"] = "voice"
+	case discordgo.ChannelTypeGuildForum, discordgo.ChannelTypeGuildMedia:
+		info["type"] = "forum"
+	case discordgo.ChannelTypeGuildNews:
+		info["type"] = "announcement"
+	case discordgo.ChannelTypeGuildPublicThread, discordgo.ChannelTypeGuildPrivateThread, discordgo.ChannelTypeGuildNewsThread:
+		info["type"] = "thread"
+	default:
+		info["type"] = "text"
+	}
+
+	return info, nil
+}
+
+func (d DiscordContext) findChannel(channels []*discordgo.Channel, query string) *discordgo.Channel {
+	if query == "" {
+		query = d.ChannelID
+	}
+	if match := channelMentionPattern.FindStringSubmatch(query); match != nil {
+		query = match[1]
+	}
+
+	if snowflakePattern.MatchString(query) {
+		if channel, err := d.Session.State.Channel(query); err == nil && channel.GuildID == d.GuildID {
+			return channel
+		}
+		if channel, err := d.Session.Channel(query); err == nil && channel.GuildID == d.GuildID {
+			return channel
+		}
+		return nil
+	}
+
+	name := strings.TrimPrefix(query, "#")
+	for _, channel := range channels {
+		if strings.EqualFold(channel.Name, name) && channel.Type != discordgo.ChannelTypeGuildCategory {
+			return channel
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bot/internal/logging"
+	"bot/internal/metrics"
+
+	"google.golang.org/genai"
+)
+
+const imageModel = "gemini-2.5-flash-image"
+
+var ImageTool = &genai.FunctionDeclaration{
+	Name:        "generateImage",
+	Description: "Generates an image from a detailed description and attaches it to the reply",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"prompt": {Type: genai.TypeString},
+		},
+		Required: []string{"prompt"},
+	},
+}
+
+// GenerateImage asks Gemini's image model for a picture using the guild's own
+// client and returns the first image it produced, along with the tokens the
+// request used.
+func GenerateImage(ctx context.Context, client *genai.Client, prompt string) (*genai.Blob, *genai.GenerateContentResponseUsageMetadata, error) {
+	logging.FromContext(ctx).Debug("Generating image", logging.Content("prompt", prompt))
+
+	start := time.Now()
+	resp, err := client.Models.GenerateContent(
+		ctx,
+		imageModel,
+		genai.Text(prompt),
+		&genai.GenerateContentConfig{
+			ResponseModalities: []string{string(genai.ModalityText), string(genai.ModalityImage)},
+		},
+	)
+	metrics.ObserveGemini("image", start, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate image: %v", err)
+	}
+
+	for _, candidate := range resp.Candidates {
+		if candidate.Content == nil {
+			continue
+		}
+
+		for _, part := range candidate.Content.Parts {
+			if part.InlineData != nil && len(part.InlineData.Data) > 0 {
+				return part.InlineData, resp.UsageMetadata, nil
+			}
+		}
+	}
+
+	return nil, resp.UsageMetadata, fmt.Errorf("the model did not return an image")
+}
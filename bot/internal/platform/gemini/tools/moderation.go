@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"bot/internal/moderation"
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+	"google.golang.org/genai"
+)
+
+var TimeoutTool = &genai.FunctionDeclaration{
+	Name:        "timeoutMember",
+	Description: "Proposes timing out a member of this Discord server. The user who asked has to confirm it with a button before it happens",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"member": {
+				Type:        genai.TypeString,
+				Description: "Mention such as <@123>, user ID, or name of the member to time out",
+			},
+			"minutes": {
+				Type:        genai.TypeInteger,
+				Description: "How long the timeout lasts in minutes, at most 40320 (28 days)",
+			},
+			"reason": {
+				Type:        genai.TypeString,
+				Description: "Why the member is timed out, shown in the audit log",
+			},
+		},
+		Required: []string{"member", "minutes"},
+	},
+}
+
+var DeleteMessagesTool = &genai.FunctionDeclaration{
+	Name:        "deleteMessages",
+	Description: "Proposes deleting the most recent messages in the current channel, optionally only those of one member. The user who asked has to confirm it with a button before it happens",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"count": {
+				Type:        genai.TypeInteger,
+				Description: "How many messages to delete, at most 100",
+			},
+			"member": {
+				Type:        genai.TypeString,
+				Description: "Only delete messages from this member. Mention such as <@123>, user ID, or name",
+			},
+			"reason": {
+				Type:        genai.TypeString,
+				Description: "Why the messages are deleted, shown in the audit log",
+			},
+		},
+		Required: []string{"count"},
+	},
+}
+
+var AddRoleTool = &genai.FunctionDeclaration{
+	Name:        "addRole",
+	Description: "Proposes giving a role to a member of this Discord server. The user who asked has to confirm it with a button before it happens",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"member": {
+				Type:        genai.TypeString,
+				Description: "Mention such as <@123>, user ID, or name of the member",
+			},
+			"role": {
+				Type:        genai.TypeString,
+				Description: "Mention such as <@&123>, role ID or name of the role to give",
+			},
+			"reason": {
+				Type:        genai.TypeString,
+				Description: "Why the role is given, shown in the audit log",
+			},
+		},
+		Required: []string{"member", "role"},
+	},
+}
+
+var roleMentionPattern = regexp.MustCompile(`^<@&(\d+)>$`)
+
+// ModerationTools returns the moderation tools the member's permissions in
+// the channel allow them to request.
+func ModerationTools(permissions int64) []*genai.FunctionDeclaration {
+	var declarations []*genai.FunctionDeclaration
+
+	if moderation.HasPermission(permissions, structs.ModerationTimeout) {
+		declarations = append(declarations, TimeoutTool)
+	}
+	if moderation.HasPermission(permissions, structs.ModerationDeleteMessages) {
+		declarations = append(declarations, DeleteMessagesTool)
+	}
+	if moderation.HasPermission(permissions, structs.ModerationAddRole) {
+		declarations = append(declarations, AddRoleTool)
+	}
+
+	return declarations
+}
+
+// ProposeModeration turns a moderation tool call into an action waiting for
+// confirmation. Nothing is changed in Discord here.
+func ProposeModeration(d DiscordContext, name string, args map[string]any) (structs.ModerationAction, error) {
+	action := structs.ModerationAction{
+		GuildID:     d.GuildID,
+		ChannelID:   d.ChannelID,
+		RequestedBy: d.UserID,
+	}
+
+	if reason, ok := args["reason"].(string); ok {
+		action.Reason = strings.TrimSpace(reason)
+	}
+
+	switch name {
+	case "timeoutMember":
+		action.Action = structs.ModerationTimeout
+
+		minutes, _ := args["minutes"].(float64)
+		if minutes < 1 || minutes > moderation.MaxTimeoutMinutes {
+			return action, fmt.Errorf("the timeout must be between 1 and %v minutes", moderation.MaxTimeoutMinutes)
+		}
+		action.Minutes = int(minutes)
+	case "deleteMessages":
+		action.Action = structs.ModerationDeleteMessages
+
+		count, _ := args["count"].(float64)
+		if count < 1 || count > moderation.MaxDeletedMessages {
+			return action, fmt.Errorf("between 1 and %v messages can be deleted at once", moderation.MaxDeletedMessages)
+		}
+		action.Count = int(count)
+	case "addRole":
+		action.Action = structs.ModerationAddRole
+
+		role, err := d.findRole(strings.TrimSpace(fmt.Sprint(args["role"])))
+		if err != nil {
+			return action, err
+		}
+		action.RoleID = role.ID
+		action.RoleName = role.Name
+	default:
+		return action, fmt.Errorf("unknown moderation tool '%v'", name)
+	}
+
+	// The tools are only offered to members with the permission, but the
+	// model could still call one it was not given.
+	permissions, err := d.Session.UserChannelPermissions(d.UserID, d.ChannelID)
+	if err != nil || !moderation.HasPermission(permissions, action.Action) {
+		return action, fmt.Errorf("you do not have the permission to do this")
+	}
+
+	// The member is optional only when deleting messages.
+	query, _ := args["member"].(string)
+	if query = strings.TrimSpace(query); query != "" || action.Action != structs.ModerationDeleteMessages {
+		if query == "" {
+			return action, fmt.Errorf("name the member the action is for")
+		}
+
+		member, err := d.findMember(query)
+		if err != nil {
+			return action, err
+		}
+		action.TargetID = member.User.ID
+		action.TargetName = member.DisplayName()
+	}
+
+	return action, moderation.CheckHierarchy(d.Session, action)
+}
+
+func (d DiscordContext) findRole(query string) (*discordgo.Role, error) {
+	guild, err := d.guild()
+	if err != nil {
+		return nil, err
+	}
+
+	if match := roleMentionPattern.FindStringSubmatch(query); match != nil {
+		query = match[1]
+	}
+	query = strings.TrimPrefix(query, "@")
+
+	for _, role := range d.roles(guild) {
+		if role.ID == query || strings.EqualFold(role.Name, query) {
+			return role, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no role '%v' was found", query)
+}
@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// MaxReminderDelay is how far ahead a reminder can be scheduled.
+const MaxReminderDelay = 365 * 24 * time.Hour
+
+var ReminderTool = &genai.FunctionDeclaration{
+	Name:        "createReminder",
+	Description: "Schedules a reminder that mentions the user in this channel at a later time. Use 'in' for relative times and 'at' for clock or calendar times",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"message": {
+				Type:        genai.TypeString,
+				Description: "What to remind the user about, such as 'check the oven'",
+			},
+			"in": {
+				Type:        genai.TypeString,
+				Description: "Delay from now, such as '2 hours', '1h30m', '3 days' or '1 week'",
+			},
+			"at": {
+				Type:        genai.TypeString,
+				Description: "Time to remind at, such as '2025-03-01 18:30' or '6pm'. Clock times that have passed today are tomorrow",
+			},
+			"location": {
+				Type:        genai.TypeString,
+				Description: "Place or time zone of 'at', UTC by default",
+			},
+		},
+		Required: []string{"message"},
+	},
+}
+
+var delayPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-z]+)`)
+
+var delayUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDelay reads delays such as '2 hours', '1h30m' or '1 day and 3 hours'.
+func ParseDelay(value string) (time.Duration, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	value = strings.TrimPrefix(value, "in ")
+
+	if value == "" {
+		return 0, fmt.Errorf("the delay is empty")
+	}
+
+	var total time.Duration
+	matched := delayPattern.FindAllStringSubmatchIndex(value, -1)
+
+	rest := value
+	for i := len(matched) - 1; i >= 0; i-- {
+		match := matched[i]
+
+		amount, err := strconv.ParseFloat(value[match[2]:match[3]], 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not read the delay '%v'", value)
+		}
+
+		unit, ok := delayUnits[value[match[4]:match[5]]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit '%v' in the delay '%v'", value[match[4]:match[5]], value)
+		}
+
+		total += time.Duration(amount * float64(unit))
+		rest = rest[:match[0]] + rest[match[1]:]
+	}
+
+	rest = strings.NewReplacer("and", "", ",", "", " ", "").Replace(rest)
+	if len(matched) == 0 || rest != "" {
+		return 0, fmt.Errorf("could not read the delay '%v', use a format like '2 hours' or '1h30m'", value)
+	}
+
+	return total, nil
+}
+
+// ReminderTime works out when a reminder is due from either a delay or a time
+// in a place or zone. Clock times that have already passed today are taken as
+// tomorrow.
+func ReminderTime(in string, at string, location string) (time.Time, error) {
+	now := time.Now()
+
+	var due time.Time
+
+	switch {
+	case strings.TrimSpace(in) != "":
+		delay, err := ParseDelay(in)
+		if err != nil {
+			return time.Time{}, err
+		}
+		due = now.Add(delay)
+	case strings.TrimSpace(at) != "":
+		if location == "" {
+			location = "UTC"
+		}
+
+		loc, err := ResolveZone(location)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		due, err = ParseTime(at, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if !due.After(now) && isClockOnly(at, loc) {
+			due = due.AddDate(0, 0, 1)
+		}
+	default:
+		return time.Time{}, fmt.Errorf("say when to remind, either a delay or a time")
+	}
+
+	if !due.After(now) {
+		return time.Time{}, fmt.Errorf("the reminder time %v is in the past", due.Format(time.RFC1123))
+	}
+	if due.Sub(now) > MaxReminderDelay {
+		return time.Time{}, fmt.Errorf("reminders can be at most a year ahead")
+	}
+
+	return due.UTC(), nil
+}
+
+func isClockOnly(value string, location *time.Location) bool {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	for _, layout := range clockLayouts {
+		if _, err := time.ParseInLocation(layout, value, location); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,15 @@
+package tools
+
+import "google.golang.org/genai"
+
+var SearchTool = &genai.FunctionDeclaration{
+	Name:        "webSearch",
+	Description: "Searches the web for up to date information. Cite results by their number, such as [1]",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"query": {Type: genai.TypeString},
+		},
+		Required: []string{"query"},
+	},
+}
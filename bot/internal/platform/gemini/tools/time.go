@@ -1,35 +1,325 @@
 package tools
 
 import (
+	_ "embed"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	// Embed the time zone database so zones resolve on hosts without one.
+	_ "time/tzdata"
+
 	"google.golang.org/genai"
 )
 
 var TimeTool = &genai.FunctionDeclaration{
 	Name:        "getTime",
-	Description: "Gets the current time",
+	Description: "Gets the current time, UTC offset and daylight saving status of a place or time zone",
 	Parameters: &genai.Schema{
 		Type: genai.TypeObject,
 		Properties: map[string]*genai.Schema{
-			"location_iana": {Type: genai.TypeString},
+			"location_iana": {
+				Type:        genai.TypeString,
+				Description: "IANA time zone such as 'Europe/Paris', a city or country name, or a UTC offset such as 'UTC+2'",
+			},
 		},
 		Required: []string{"location_iana"},
 	},
 }
 
-func GetTime(location string) time.Time {
-	timeNow := time.Now()
+var ConvertTimeTool = &genai.FunctionDeclaration{
+	Name:        "convertTime",
+	Description: "Converts a date and time from one place or time zone to another",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"time": {
+				Type:        genai.TypeString,
+				Description: "Time to convert, such as '2025-03-01 18:30', '18:30', '6pm' or 'now'. Times without a date are today",
+			},
+			"from": {Type: genai.TypeString, Description: "Place or time zone the time is in"},
+			"to":   {Type: genai.TypeString, Description: "Place or time zone to convert to"},
+		},
+		Required: []string{"time", "from", "to"},
+	},
+}
+
+var TimeDifferenceTool = &genai.FunctionDeclaration{
+	Name:        "timeDifference",
+	Description: "Computes the duration between two dates and times",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"start":    {Type: genai.TypeString, Description: "Start time, such as '2025-03-01 18:30' or 'now'"},
+			"end":      {Type: genai.TypeString, Description: "End time, such as '2025-12-25' or 'now'"},
+			"location": {Type: genai.TypeString, Description: "Place or time zone of times without a UTC offset, UTC by default"},
+		},
+		Required: []string{"start", "end"},
+	},
+}
+
+//go:embed zones.tsv
+var zonesTable string
+
+// placeZones maps lowercase place names to the IANA zones they use.
+var placeZones = parseZones(zonesTable)
+
+func parseZones(table string) map[string][]string {
+	zones := make(map[string][]string)
+
+	for _, line := range strings.Split(table, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, list, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		zones[name] = strings.Split(list, ",")
+	}
+
+	return zones
+}
+
+var offsetPattern = regexp.MustCompile(`^(?i)(?:utc|gmt)?\s*([+-])(\d{1,2})(?::?(\d{2}))?$`)
+
+// ResolveZone turns an IANA zone, a place name or a UTC offset into a
+// location. Unknown names and places spanning several zones are errors rather
+// than a silent fallback to the server's zone.
+func ResolveZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("no location was given")
+	}
+
+	if strings.EqualFold(name, "utc") || strings.EqualFold(name, "gmt") || strings.EqualFold(name, "z") {
+		return time.UTC, nil
+	}
+
+	if match := offsetPattern.FindStringSubmatch(name); match != nil {
+		hours, _ := strconv.Atoi(match[2])
+		minutes, _ := strconv.Atoi(match[3])
+		if hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("'%v' is not a valid UTC offset", name)
+		}
+
+		offset := hours*3600 + minutes*60
+		if match[1] == "-" {
+			offset = -offset
+		}
+
+		return time.FixedZone(strings.ToUpper(name), offset), nil
+	}
+
+	// "Local" would silently give the server's zone.
+	if !strings.EqualFold(name, "local") {
+		if location, err := time.LoadLocation(name); err == nil {
+			return location, nil
+		}
+	}
+
+	key := strings.Join(strings.Fields(strings.ToLower(name)), " ")
+	candidates := []string{key}
+
+	// "Paris, France" tries the city first and then the country.
+	if parts := strings.Split(key, ","); len(parts) > 1 {
+		candidates = append(candidates, strings.TrimSpace(parts[0]), strings.TrimSpace(parts[len(parts)-1]))
+	}
+
+	for _, candidate := range candidates {
+		zones, ok := placeZones[candidate]
+		if !ok {
+			continue
+		}
+
+		if len(zones) > 1 {
+			listed := zones
+			if len(listed) > 6 {
+				listed = listed[:6]
+			}
+			return nil, fmt.Errorf("'%v' spans %v time zones, such as %v. Ask which city or zone is meant", name, len(zones), strings.Join(listed, ", "))
+		}
+
+		return time.LoadLocation(zones[0])
+	}
+
+	return nil, fmt.Errorf("'%v' is not a known time zone or place", name)
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02 3:04PM",
+	"2006-01-02 3PM",
+	"2006-01-02",
+}
+
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+	"3:04PM",
+	"3:04 PM",
+	"3PM",
+	"3 PM",
+}
+
+// ParseTime reads a time in the given location. Times without a date are
+// taken as today in that location, and times with an explicit offset keep it.
+func ParseTime(value string, location *time.Location) (time.Time, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	if value == "NOW" || value == "" {
+		return time.Now().In(location), nil
+	}
 
-	timeLocation, err := time.LoadLocation(location)
+	for _, layout := range timeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	for _, layout := range clockLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			now := time.Now().In(location)
+			return time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, location), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not read the time '%v', use a format like '2025-03-01 18:30' or '6pm'", value)
+}
+
+// describeTime reports a time along with its zone details, including whether
+// daylight saving is in effect and when the offset next changes.
+func describeTime(t time.Time) map[string]any {
+	abbreviation, offset := t.Zone()
+
+	result := map[string]any{
+		"time":         t.Format("Monday, 2 January 2006 15:04:05"),
+		"iso":          t.Format(time.RFC3339),
+		"zone":         t.Location().String(),
+		"abbreviation": abbreviation,
+		"utc_offset":   formatOffset(offset),
+		"is_dst":       t.IsDST(),
+	}
+
+	if _, end := t.ZoneBounds(); !end.IsZero() {
+		_, nextOffset := end.Zone()
+		result["next_offset_change"] = end.Format(time.RFC3339)
+		result["offset_after_change"] = formatOffset(nextOffset)
+	}
+
+	return result
+}
+
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+
+	return fmt.Sprintf("UTC%v%02d:%02d", sign, offset/3600, offset%3600/60)
+}
+
+func GetTime(location string) (map[string]any, error) {
+	timeLocation, err := ResolveZone(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeTime(time.Now().In(timeLocation)), nil
+}
+
+func ConvertTime(value string, from string, to string) (map[string]any, error) {
+	fromLocation, err := ResolveZone(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toLocation, err := ResolveZone(to)
 	if err != nil {
-		fmt.Println("Error while loading time location:", err)
-		return timeNow
+		return nil, err
 	}
 
-	timeInLocation := timeNow.In(timeLocation)
-	fmt.Println("Time in location:", timeInLocation)
+	parsed, err := ParseTime(value, fromLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"from": describeTime(parsed),
+		"to":   describeTime(parsed.In(toLocation)),
+	}, nil
+}
+
+func TimeDifference(start string, end string, location string) (map[string]any, error) {
+	timeLocation := time.UTC
+	if location != "" {
+		var err error
+		timeLocation, err = ResolveZone(location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	startTime, err := ParseTime(start, timeLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	endTime, err := ParseTime(end, timeLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	difference := endTime.Sub(startTime)
+
+	return map[string]any{
+		"start":         startTime.Format(time.RFC3339),
+		"end":           endTime.Format(time.RFC3339),
+		"duration":      formatDuration(difference),
+		"total_seconds": int64(difference.Seconds()),
+		"end_is_after":  difference >= 0,
+	}, nil
+}
+
+func formatDuration(duration time.Duration) string {
+	if duration < 0 {
+		duration = -duration
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+
+	parts := []string{}
+	for _, unit := range units {
+		count := duration / unit.size
+		duration -= count * unit.size
+
+		if count == 1 {
+			parts = append(parts, "1 "+unit.name)
+		} else if count > 1 {
+			parts = append(parts, fmt.Sprintf("%v %vs", int64(count), unit.name))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
 
-	return timeInLocation
+	return strings.Join(parts, ", ")
 }
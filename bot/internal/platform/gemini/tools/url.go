@@ -0,0 +1,18 @@
+package tools
+
+import "google.golang.org/genai"
+
+var FetchURLTool = &genai.FunctionDeclaration{
+	Name:        "fetchUrl",
+	Description: "Reads the text of a web page or plain text file, for when the user shares a link or asks what a page says",
+	Parameters: &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"url": {
+				Type:        genai.TypeString,
+				Description: "Full http or https address of the page",
+			},
+		},
+		Required: []string{"url"},
+	},
+}
@@ -1,52 +1,266 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"bot/internal/logging"
+	"bot/internal/structs"
 
 	"google.golang.org/genai"
 )
 
 var WeatherTool = &genai.FunctionDeclaration{
 	Name:        "getWeather",
-	Description: "Gets the current weather in a town, city, state, prefecture, province, or country",
+	Description: "Gets the current weather or a forecast of up to 5 days in a town, city, state, prefecture, province, or country",
 	Parameters: &genai.Schema{
 		Type: genai.TypeObject,
 		Properties: map[string]*genai.Schema{
-			"location": {Type: genai.TypeString},
+			"location": {
+				Type:        genai.TypeString,
+				Description: "Place name, optionally followed by a state and country code, such as 'Paris, FR'",
+			},
+			"units": {
+				Type:        genai.TypeString,
+				Description: "Units for the result, metric by default",
+				Enum:        []string{"metric", "imperial", "standard"},
+			},
+			"days": {
+				Type:        genai.TypeInteger,
+				Description: "Number of forecast days from 1 to 5, or 0 for only the current weather",
+			},
 		},
 		Required: []string{"location"},
 	},
 }
 
-func GetWeather(apiKey string, location string) (string, error) {
-	fmt.Println("Location to fetch:", location)
+var weatherClient = &http.Client{Timeout: 10 * time.Second}
+
+// weatherBaseURL returns the OpenWeatherMap base URL, which can be pointed at
+// a local stub with OPENWEATHERMAP_BASE_URL.
+func weatherBaseURL() string {
+	if baseURL := os.Getenv("OPENWEATHERMAP_BASE_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
 
-	urlToFetch := "https://api.openweathermap.org/data/2.5/weather?q=" + location + "&appid=" + apiKey
+	return "https://api.openweathermap.org"
+}
 
-	resp, err := http.Get(urlToFetch)
+func fetchWeatherJSON(ctx context.Context, path string, params url.Values, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, weatherBaseURL()+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("error creating weather request: %v", err)
+	}
 
+	resp, err := weatherClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("error fetching weather: %v", err)
+		// The error includes the request URL and with it the API key, and
+		// it is passed on to the model, so only the log gets the details.
+		logging.FromContext(ctx).Warn("Weather request failed", "path", path, "error", err)
+
+		return fmt.Errorf("the weather service could not be reached")
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		bodyString := string(bodyBytes)
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		logging.FromContext(ctx).Warn("Weather request failed", "path", path, "status", resp.StatusCode, "body", string(bodyBytes))
 
-		fmt.Println("Fetch failed:", bodyString)
+		return fmt.Errorf("error fetching weather: status %v", resp.StatusCode)
+	}
 
-		return "", fmt.Errorf("error fetching weather: %v", err)
+	err = json.NewDecoder(resp.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("failed to decode weather response: %v", err)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	return nil
+}
+
+// geocode resolves a place name to coordinates. The best match is used, and
+// the other matches are returned so the model can ask which one was meant.
+func geocode(ctx context.Context, apiKey string, location string) (structs.GeocodedLocation, []structs.GeocodedLocation, error) {
+	var locations []structs.GeocodedLocation
+
+	err := fetchWeatherJSON(ctx, "/geo/1.0/direct", url.Values{
+		"q":     {location},
+		"limit": {"5"},
+		"appid": {apiKey},
+	}, &locations)
 	if err != nil {
-		fmt.Println("Error while reading body:", err)
-		return "", fmt.Errorf("failed to read body: %v", err)
+		return structs.GeocodedLocation{}, nil, err
+	}
+
+	if len(locations) == 0 {
+		return structs.GeocodedLocation{}, nil, fmt.Errorf("no place called '%v' was found", location)
+	}
+
+	return locations[0], locations[1:], nil
+}
+
+func describeLocation(location structs.GeocodedLocation) string {
+	parts := []string{location.Name}
+	if location.State != "" {
+		parts = append(parts, location.State)
+	}
+	if location.Country != "" {
+		parts = append(parts, location.Country)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func unitSymbols(units string) (string, string) {
+	switch units {
+	case "imperial":
+		return "°F", "mph"
+	case "standard":
+		return "K", "m/s"
+	default:
+		return "°C", "m/s"
+	}
+}
+
+// GetWeather returns a short summary of the current weather at a location,
+// followed by a daily forecast when days is between 1 and 5.
+func GetWeather(ctx context.Context, apiKey string, location string, units string, days int) (string, error) {
+	logging.FromContext(ctx).Debug("Fetching weather", logging.Content("location", location))
+
+	if units != "imperial" && units != "standard" {
+		units = "metric"
+	}
+	days = max(0, min(days, 5))
+
+	if strings.TrimSpace(location) == "" {
+		return "", fmt.Errorf("no location was given")
+	}
+
+	place, alternatives, err := geocode(ctx, apiKey, location)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"lat":   {fmt.Sprint(place.Lat)},
+		"lon":   {fmt.Sprint(place.Lon)},
+		"units": {units},
+		"appid": {apiKey},
+	}
+
+	var weather structs.FetchedWeather
+	err = fetchWeatherJSON(ctx, "/data/2.5/weather", params, &weather)
+	if err != nil {
+		return "", err
+	}
+
+	temperatureUnit, speedUnit := unitSymbols(units)
+
+	var summary strings.Builder
+
+	fmt.Fprintf(&summary, "%v: ", describeLocation(place))
+	if len(weather.Weather) > 0 {
+		fmt.Fprintf(&summary, "%v, ", weather.Weather[0].Description)
+	}
+	fmt.Fprintf(&summary, "%.1f%v (feels like %.1f%v), low %.1f%v, high %.1f%v, humidity %v%%, wind %.1f %v, clouds %v%%.",
+		weather.Main.Temp, temperatureUnit,
+		weather.Main.FeelsLike, temperatureUnit,
+		weather.Main.TempMin, temperatureUnit,
+		weather.Main.TempMax, temperatureUnit,
+		weather.Main.Humidity,
+		weather.Wind.Speed, speedUnit,
+		weather.Clouds.All,
+	)
+
+	if days > 0 {
+		var forecast structs.FetchedForecast
+		err = fetchWeatherJSON(ctx, "/data/2.5/forecast", params, &forecast)
+		if err != nil {
+			return "", err
+		}
+
+		summary.WriteString(" Forecast: ")
+		summary.WriteString(summarizeForecast(forecast, days, temperatureUnit))
+	}
+
+	if len(alternatives) > 0 {
+		others := make([]string, 0, len(alternatives))
+		for _, alternative := range alternatives {
+			others = append(others, describeLocation(alternative))
+		}
+		fmt.Fprintf(&summary, " Other places with this name: %v.", strings.Join(others, "; "))
+	}
+
+	return summary.String(), nil
+}
+
+// summarizeForecast folds the 3-hourly forecast into one line per local day
+// with the temperature range, the most frequent conditions and the highest
+// chance of precipitation.
+func summarizeForecast(forecast structs.FetchedForecast, days int, temperatureUnit string) string {
+	type daySummary struct {
+		date       string
+		low, high  float64
+		conditions map[string]int
+		pop        float64
+	}
+
+	zone := time.FixedZone("", forecast.City.Timezone)
+	summaries := []*daySummary{}
+
+	for _, entry := range forecast.List {
+		date := time.Unix(entry.Dt, 0).In(zone).Format("Mon 2 Jan")
+
+		if len(summaries) == 0 || summaries[len(summaries)-1].date != date {
+			if len(summaries) == days {
+				break
+			}
+			summaries = append(summaries, &daySummary{
+				date:       date,
+				low:        entry.Main.TempMin,
+				high:       entry.Main.TempMax,
+				conditions: map[string]int{},
+			})
+		}
+
+		day := summaries[len(summaries)-1]
+		day.low = min(day.low, entry.Main.TempMin)
+		day.high = max(day.high, entry.Main.TempMax)
+		day.pop = max(day.pop, entry.Pop)
+		if len(entry.Weather) > 0 {
+			day.conditions[entry.Weather[0].Description]++
+		}
+	}
+
+	lines := make([]string, 0, len(summaries))
+	for _, day := range summaries {
+		conditions := make([]string, 0, len(day.conditions))
+		for condition := range day.conditions {
+			conditions = append(conditions, condition)
+		}
+		slices.SortFunc(conditions, func(a, b string) int {
+			if day.conditions[a] != day.conditions[b] {
+				return day.conditions[b] - day.conditions[a]
+			}
+			return strings.Compare(a, b)
+		})
+
+		condition := "unknown"
+		if len(conditions) > 0 {
+			condition = conditions[0]
+		}
+
+		lines = append(lines, fmt.Sprintf("%v: %v, %.0f-%.0f%v, %.0f%% chance of precipitation",
+			day.date, condition, day.low, day.high, temperatureUnit, day.pop*100))
 	}
 
-	return string(bodyBytes), nil
+	return strings.Join(lines, "; ") + "."
 }
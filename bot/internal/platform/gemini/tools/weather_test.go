@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testWeatherKey = "test-key"
+
+// newWeatherServer stubs the OpenWeatherMap endpoints the weather tool uses
+// and points the tool at it.
+func newWeatherServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}
+
+	mux.HandleFunc("/geo/1.0/direct", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "Paris" {
+			reply(w, []any{})
+			return
+		}
+		reply(w, []map[string]any{
+			{"name": "Paris", "lat": 48.85, "lon": 2.35, "country": "FR"},
+			{"name": "Paris", "lat": 33.66, "lon": -95.56, "country": "US", "state": "Texas"},
+		})
+	})
+	mux.HandleFunc("/data/2.5/weather", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lat") != "48.85" || r.URL.Query().Get("units") != "metric" {
+			t.Errorf("unexpected weather query %v", r.URL.RawQuery)
+		}
+		reply(w, map[string]any{
+			"weather": []map[string]any{{"description": "clear sky"}},
+			"main": map[string]any{
+				"temp": 12.34, "feels_like": 11, "temp_min": 10, "temp_max": 14, "humidity": 60,
+			},
+			"wind":   map[string]any{"speed": 3.5},
+			"clouds": map[string]any{"all": 0},
+		})
+	})
+	mux.HandleFunc("/data/2.5/forecast", func(w http.ResponseWriter, r *http.Request) {
+		entry := func(dt int64, low, high float64, description string, pop float64) map[string]any {
+			return map[string]any{
+				"dt":      dt,
+				"main":    map[string]any{"temp_min": low, "temp_max": high},
+				"weather": []map[string]any{{"description": description}},
+				"pop":     pop,
+			}
+		}
+		reply(w, map[string]any{
+			"list": []map[string]any{
+				entry(1700006400, 5, 8, "light rain", 0.4),
+				entry(1700006400+3*3600, 4, 9, "light rain", 0.8),
+				entry(1700006400+24*3600, 6, 12, "clear sky", 0),
+			},
+			"city": map[string]any{"name": "Paris", "timezone": 0},
+		})
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != testWeatherKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("OPENWEATHERMAP_BASE_URL", server.URL)
+
+	return server
+}
+
+func TestGetWeather(t *testing.T) {
+	newWeatherServer(t)
+
+	current := "Paris, FR: clear sky, 12.3°C (feels like 11.0°C), low 10.0°C, high 14.0°C, humidity 60%, wind 3.5 m/s, clouds 0%."
+	others := " Other places with this name: Paris, Texas, US."
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		location string
+		days     int
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "current weather",
+			apiKey:   testWeatherKey,
+			location: "Paris",
+			want:     current + others,
+		},
+		{
+			name:     "forecast grouped by day",
+			apiKey:   testWeatherKey,
+			location: "Paris",
+			days:     1,
+			want:     current + " Forecast: Wed 15 Nov: light rain, 4-9°C, 80% chance of precipitation." + others,
+		},
+		{
+			name:     "unknown place",
+			apiKey:   testWeatherKey,
+			location: "Nowhere",
+			wantErr:  "no place called 'Nowhere' was found",
+		},
+		{
+			name:     "empty location",
+			apiKey:   testWeatherKey,
+			location: " ",
+			wantErr:  "no location was given",
+		},
+		{
+			name:     "rejected key",
+			apiKey:   "wrong-key",
+			location: "Paris",
+			wantErr:  "status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetWeather(context.Background(), tt.apiKey, tt.location, "", tt.days)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GetWeather() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetWeather() returned an error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetWeather() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetWeatherUnreachableHidesKey(t *testing.T) {
+	server := newWeatherServer(t)
+	server.Close()
+
+	_, err := GetWeather(context.Background(), testWeatherKey, "Paris", "", 0)
+	if err == nil {
+		t.Fatal("GetWeather() returned no error for an unreachable server")
+	}
+	if strings.Contains(err.Error(), testWeatherKey) {
+		t.Errorf("GetWeather() error contains the API key: %v", err)
+	}
+}
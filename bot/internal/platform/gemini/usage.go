@@ -0,0 +1,67 @@
+package gemini
+
+import (
+	"fmt"
+
+	"bot/internal/structs"
+
+	"google.golang.org/genai"
+)
+
+// tokenUsage converts the usage a response reported. A response without
+// usage metadata still counts as a request.
+func tokenUsage(metadata *genai.GenerateContentResponseUsageMetadata) structs.TokenUsage {
+	usage := structs.TokenUsage{Requests: 1}
+	if metadata == nil {
+		return usage
+	}
+
+	usage.Prompt = int64(metadata.PromptTokenCount)
+	usage.Candidates = int64(metadata.CandidatesTokenCount)
+	usage.Tool = int64(metadata.ToolUsePromptTokenCount)
+	usage.Thoughts = int64(metadata.ThoughtsTokenCount)
+	usage.Total = int64(metadata.TotalTokenCount)
+
+	return usage
+}
+
+// recordUsage stores what was spent on the guild's key for the author of the
+// message.
+func (r *APIRequest) recordUsage(usage structs.TokenUsage) {
+	if usage.Requests == 0 {
+		return
+	}
+
+	err := r.Usage.Record(r.M.GuildID, r.M.Author.ID, usage)
+	if err != nil {
+		r.logger().Error("Error while recording usage", "error", err)
+	}
+}
+
+// checkBudget returns an error for the user when the guild has spent its
+// daily or monthly token budget.
+func (r *APIRequest) checkBudget() error {
+	budget, err := r.Repository.FetchUsageBudget(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching usage budget", "error", err)
+		return nil
+	}
+	if budget.DailyTokens <= 0 && budget.MonthlyTokens <= 0 {
+		return nil
+	}
+
+	today, month, err := r.Usage.Totals(r.M.GuildID)
+	if err != nil {
+		r.logger().Error("Error while fetching usage", "error", err)
+		return nil
+	}
+
+	if budget.DailyTokens > 0 && today.Total >= budget.DailyTokens {
+		return fmt.Errorf("This server has used its daily AI budget of %v tokens. It resets at midnight UTC.", budget.DailyTokens)
+	}
+	if budget.MonthlyTokens > 0 && month.Total >= budget.MonthlyTokens {
+		return fmt.Errorf("This server has used its monthly AI budget of %v tokens. It resets at the start of next month (UTC).", budget.MonthlyTokens)
+	}
+
+	return nil
+}
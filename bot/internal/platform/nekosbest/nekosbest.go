@@ -0,0 +1,97 @@
+package nekosbest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	nb "github.com/Yakiyo/nekos_best.go"
+)
+
+// The nekos_best.go library keeps its category lists private, so they are
+// repeated here for command choices and validation.
+var ImageCategories = []string{"husbando", "kitsune", "neko", "waifu"}
+
+var GifCategories = []string{
+	"angry", "baka", "bite", "blush", "bonk", "bored", "cry", "cuddle", "dance",
+	"facepalm", "feed", "handhold", "handshake", "happy", "highfive", "hug",
+	"kick", "kiss", "laugh", "lurk", "nibble", "nod", "nom", "nope", "pat",
+	"peck", "poke", "pout", "punch", "run", "shoot", "shrug", "slap", "sleep",
+	"smile", "smug", "stare", "tableflip", "think", "thumbsup", "tickle", "wave",
+	"wink", "yawn", "yeet",
+}
+
+func IsImageCategory(category string) bool {
+	return slices.Contains(ImageCategories, category)
+}
+
+func IsGifCategory(category string) bool {
+	return slices.Contains(GifCategories, category)
+}
+
+const baseURL = "https://nekos.best/api/v2"
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Fetch returns random results of a category. The library's own FetchMany
+// cannot be cancelled, so the endpoint is called directly.
+func Fetch(ctx context.Context, category string, amount int) ([]nb.NBResponse, error) {
+	if !IsImageCategory(category) && !IsGifCategory(category) {
+		return nil, fmt.Errorf("category '%v' is not valid", category)
+	}
+
+	params := url.Values{"amount": {fmt.Sprint(amount)}}
+
+	return fetchResults(ctx, baseURL+"/"+url.PathEscape(category)+"?"+params.Encode())
+}
+
+// Search looks up results of a category by artist name for image categories
+// or by anime name for GIF categories. The library's own Search queries the
+// category endpoint instead of /search, so it is not used.
+func Search(ctx context.Context, query string, category string, amount int) ([]nb.NBResponse, error) {
+	searchType := "2"
+	if IsImageCategory(category) {
+		searchType = "1"
+	} else if !IsGifCategory(category) {
+		return nil, fmt.Errorf("category '%v' is not valid", category)
+	}
+
+	params := url.Values{
+		"query":    {query},
+		"type":     {searchType},
+		"category": {category},
+		"amount":   {fmt.Sprint(amount)},
+	}
+
+	return fetchResults(ctx, baseURL+"/search?"+params.Encode())
+}
+
+func fetchResults(ctx context.Context, urlToFetch string) ([]nb.NBResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlToFetch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch from nekos.best: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch from nekos.best: status %v", resp.StatusCode)
+	}
+
+	var results struct {
+		Results []nb.NBResponse `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode nekos.best results: %v", err)
+	}
+
+	return results.Results, nil
+}
@@ -0,0 +1,75 @@
+package nekosbest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"bot/internal/images"
+
+	nb "github.com/Yakiyo/nekos_best.go"
+)
+
+// Provider serves the nekos.best image categories. It can also fetch the GIF
+// categories, which are only used for reactions.
+type Provider struct{}
+
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+func (p *Provider) Name() string {
+	return "nekos.best"
+}
+
+func (p *Provider) Categories() []string {
+	return ImageCategories
+}
+
+// SafeCategories returns every category, as nekos.best only serves images
+// that are safe for work.
+func (p *Provider) SafeCategories() []string {
+	return slices.Concat(ImageCategories, GifCategories)
+}
+
+func (p *Provider) Fetch(ctx context.Context, category string, count int) ([]images.Image, error) {
+	res, err := Fetch(ctx, category, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return toImages(res), nil
+}
+
+func (p *Provider) Search(ctx context.Context, query string, category string, count int) ([]images.Image, error) {
+	res, err := Search(ctx, query, category, count)
+	if err != nil {
+		return nil, err
+	}
+
+	return toImages(res), nil
+}
+
+func toImages(res []nb.NBResponse) []images.Image {
+	results := make([]images.Image, 0, len(res))
+
+	for _, result := range res {
+		results = append(results, images.Image{
+			URL:         result.Url,
+			Attribution: attribution(result),
+			Artist:      result.Artist_name,
+		})
+	}
+
+	return results
+}
+
+// attribution credits the artist for images and the anime for GIFs, which is
+// what nekos.best returns for each.
+func attribution(res nb.NBResponse) string {
+	if res.Artist_name != "" {
+		return fmt.Sprintf("Fetched from [nekos.best](https://nekos.best). View the original image [here](%v). Artist: [%v](%v)", res.Source_url, res.Artist_name, res.Artist_href)
+	}
+
+	return fmt.Sprintf("Fetched from [nekos.best](https://nekos.best). Anime: %v", res.Anime_name)
+}
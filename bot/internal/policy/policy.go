@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// MentionFeature is the feature name used for AI replies to mentions, next to
+// the command names.
+const MentionFeature = "mention"
+
+// AdminPermission is the permission that marks a member as a server admin.
+// Admins are never restricted by the policy.
+const AdminPermission int64 = discordgo.PermissionManageGuild
+
+func IsAdmin(permissions int64) bool {
+	return permissions&(AdminPermission|discordgo.PermissionAdministrator) != 0
+}
+
+// Check reports whether a member with the given roles and permissions may use
+// a feature in a channel. The returned error explains the refusal and can be
+// shown to the member as is.
+func Check(policy structs.GuildPolicy, feature string, channelID string, roles []string, permissions int64) error {
+	if IsAdmin(permissions) {
+		return nil
+	}
+
+	if slices.Contains(policy.AdminCommands, feature) {
+		return fmt.Errorf("This is limited to server admins.")
+	}
+
+	if len(policy.AllowedChannels) > 0 && !slices.Contains(policy.AllowedChannels, channelID) {
+		return fmt.Errorf("The bot cannot be used in this channel.")
+	}
+
+	for _, role := range roles {
+		if slices.Contains(policy.DeniedRoles, role) {
+			return fmt.Errorf("One of your roles is not allowed to use the bot.")
+		}
+	}
+
+	if len(policy.AllowedRoles) > 0 && !slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(policy.AllowedRoles, role)
+	}) {
+		return fmt.Errorf("You do not have a role that is allowed to use the bot.")
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package response
 import (
 	"fmt"
 
+	"bot/internal/logging"
+
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -11,7 +13,7 @@ func DeferResponse(s *discordgo.Session, i *discordgo.InteractionCreate, msg str
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 	if err != nil {
-		fmt.Println("Error deferring:", err)
+		logging.ForInteraction(i).Error("Error deferring", "error", err)
 		return fmt.Errorf("failed to defer response: %v", err)
 	}
 
@@ -20,9 +22,24 @@ func DeferResponse(s *discordgo.Session, i *discordgo.InteractionCreate, msg str
 		Content: &temporaryMessage,
 	})
 	if err != nil {
-		fmt.Println("Error setting temporary message:", err)
+		logging.ForInteraction(i).Error("Error setting temporary message", "error", err)
 		return fmt.Errorf("failed to set temporary message: %v", err)
 	}
 
 	return nil
 }
+
+func DeferEphemeralResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Error deferring", "error", err)
+		return fmt.Errorf("failed to defer response: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package response
+
+import (
+	"fmt"
+
+	"bot/internal/logging"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func RespondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) error {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: msg,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Error responding to interaction", "error", err)
+		return fmt.Errorf("failed to respond to interaction: %v", err)
+	}
+
+	return nil
+}
+
+func FollowUpEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, msg string) error {
+	_, err := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
+		Content: msg,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		logging.ForInteraction(i).Error("Error sending follow-up message", "error", err)
+		return fmt.Errorf("failed to send follow-up message: %v", err)
+	}
+
+	return nil
+}
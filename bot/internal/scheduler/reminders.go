@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bot/internal/storage/mongodb"
+	"bot/internal/structs"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const reminderInterval = 15 * time.Second
+
+// maxReminderAttempts is how many times a reminder is posted before it is
+// given up on, for example when the channel was deleted.
+const maxReminderAttempts = 3
+
+const reminderRetryDelay = time.Minute
+
+// StartReminderScheduler posts reminders as they become due. Reminders are
+// stored in Mongo, so ones that came due while the bot was offline are sent
+// on the first run after it starts.
+func StartReminderScheduler(ctx context.Context, s *discordgo.Session, repository *mongodb.ReminderRepository) {
+	ticker := time.NewTicker(reminderInterval)
+
+	go func() {
+		sendDueReminders(s, repository)
+
+		for {
+			select {
+			case <-ticker.C:
+				sendDueReminders(s, repository)
+			case <-ctx.Done():
+				ticker.Stop()
+				slog.Info("Reminder scheduler shutting down")
+				return
+			}
+		}
+	}()
+}
+
+func sendDueReminders(s *discordgo.Session, repository *mongodb.ReminderRepository) {
+	for {
+		reminder, err := repository.ClaimDue(time.Now())
+		if err != nil {
+			slog.Error("Error while claiming reminder", "error", err)
+			return
+		}
+		if reminder == nil {
+			return
+		}
+
+		sendReminder(s, repository, reminder)
+	}
+}
+
+func sendReminder(s *discordgo.Session, repository *mongodb.ReminderRepository, reminder *structs.Reminder) {
+	_, err := s.ChannelMessageSendComplex(reminder.ChannelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("<@%v> Reminder: %v", reminder.UserID, reminder.Message),
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Users: []string{reminder.UserID},
+		},
+	})
+
+	if err == nil {
+		err = repository.SetStatus(reminder.ID, structs.ReminderSent)
+		if err != nil {
+			slog.Error("Error while marking reminder as sent", "reminder_id", reminder.ID.Hex(), "error", err)
+		}
+		return
+	}
+
+	slog.Warn("Error while sending reminder", "reminder_id", reminder.ID.Hex(), "attempts", reminder.Attempts, "error", err)
+
+	if reminder.Attempts >= maxReminderAttempts {
+		err = repository.SetStatus(reminder.ID, structs.ReminderFailed)
+	} else {
+		err = repository.Retry(reminder.ID, time.Now().Add(reminderRetryDelay))
+	}
+	if err != nil {
+		slog.Error("Error while updating reminder", "reminder_id", reminder.ID.Hex(), "error", err)
+	}
+}
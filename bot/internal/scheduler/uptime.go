@@ -2,29 +2,41 @@ package scheduler
 
 import (
 	"context"
-	"fmt"
-	"strconv"
+	"log/slog"
 	"time"
 
+	"bot/internal/health"
 	"bot/internal/serverping"
 )
 
-const pingInterval = 10 * time.Second
-
-func StartUptimePingScheduler(startTime time.Time, ctx context.Context) {
-	ticker := time.NewTicker(pingInterval)
+const (
+	pingInterval    = 10 * time.Second
+	maxPingInterval = 5 * time.Minute
+)
 
+// StartUptimePingScheduler pushes the health report to serverURL. After a
+// failed report the interval doubles, up to maxPingInterval, so that an
+// unreachable server is not flooded.
+func StartUptimePingScheduler(ctx context.Context, checker *health.Checker, serverURL string, secret string) {
 	go func() {
+		interval := pingInterval
+		timer := time.NewTimer(interval)
+
 		for {
 			select {
-			case <-ticker.C:
-				fmt.Println("Time now: " + time.Now().Format("12:00:00"))
-				timeSinceStart := time.Since(startTime)
-				fmt.Println("Time since start: " + strconv.FormatInt(timeSinceStart.Milliseconds(), 10))
-				serverping.SendUptime(timeSinceStart.Milliseconds())
+			case <-timer.C:
+				err := serverping.SendUptime(ctx, serverURL, secret, checker.Check(ctx))
+				if err != nil {
+					interval = min(interval*2, maxPingInterval)
+					slog.Warn("Error while reporting uptime", "retry_in", interval.String(), "error", err)
+				} else {
+					interval = pingInterval
+				}
+
+				timer.Reset(interval)
 			case <-ctx.Done():
-				ticker.Stop()
-				fmt.Println("Scheduler shutting down.")
+				timer.Stop()
+				slog.Info("Uptime scheduler shutting down")
 				return
 			}
 		}
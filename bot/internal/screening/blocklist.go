@@ -0,0 +1,130 @@
+package screening
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Blocklist blocks messages containing any of its words or phrases. Words
+// only match whole, so blocking "ass" does not block "class".
+type Blocklist struct {
+	words    []string
+	patterns []*regexp.Regexp
+
+	// textPatterns find the words in text that was not normalized, for
+	// replacing them.
+	textPatterns []*regexp.Regexp
+}
+
+func NewBlocklist(words []string) *Blocklist {
+	blocklist := &Blocklist{}
+
+	for _, word := range words {
+		word = Normalize(word)
+		if word == "" {
+			continue
+		}
+
+		blocklist.words = append(blocklist.words, word)
+		blocklist.patterns = append(blocklist.patterns, regexp.MustCompile(regexp.QuoteMeta(word)))
+
+		parts := strings.Fields(word)
+		for idx, part := range parts {
+			parts[idx] = regexp.QuoteMeta(part)
+		}
+		blocklist.textPatterns = append(blocklist.textPatterns, regexp.MustCompile(`(?i)`+strings.Join(parts, `\s+`)))
+	}
+
+	return blocklist
+}
+
+func (b *Blocklist) Name() string {
+	return "blocklist"
+}
+
+// Match returns the first blocked word found in the text, or an empty string.
+func (b *Blocklist) Match(text string) string {
+	if len(b.words) == 0 {
+		return ""
+	}
+
+	normalized := Normalize(text)
+	for idx, pattern := range b.patterns {
+		if len(wholeWords(pattern, normalized, 1)) > 0 {
+			return b.words[idx]
+		}
+	}
+
+	return ""
+}
+
+// Replace passes every blocked word in the text through mask.
+func (b *Blocklist) Replace(text string, mask func(word string) string) string {
+	for _, pattern := range b.textPatterns {
+		matches := wholeWords(pattern, text, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var replaced strings.Builder
+		last := 0
+		for _, match := range matches {
+			replaced.WriteString(text[last:match[0]])
+			replaced.WriteString(mask(text[match[0]:match[1]]))
+			last = match[1]
+		}
+		replaced.WriteString(text[last:])
+
+		text = replaced.String()
+	}
+
+	return text
+}
+
+// wholeWords returns the positions of at most n matches of the pattern that
+// are not part of a longer word, or of all of them if n is negative. The
+// characters around a match are only looked at, not consumed, so repeated
+// words next to each other all match.
+func wholeWords(pattern *regexp.Regexp, text string, n int) [][]int {
+	var matches [][]int
+
+	for pos := 0; pos < len(text) && n != 0; {
+		loc := pattern.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+
+		start, end := pos+loc[0], pos+loc[1]
+
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if start > 0 && isWordRune(before) || end < len(text) && isWordRune(after) {
+			// Try again from the next character, as a whole word may start
+			// inside this match.
+			_, size := utf8.DecodeRuneInString(text[start:])
+			pos = start + size
+			continue
+		}
+
+		matches = append(matches, []int{start, end})
+		pos = max(end, start+1)
+		n--
+	}
+
+	return matches
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+func (b *Blocklist) Check(ctx context.Context, text string) (Verdict, error) {
+	if word := b.Match(text); word != "" {
+		return Verdict{Blocked: true, Reason: "contains the blocked word '" + word + "'"}, nil
+	}
+
+	return Verdict{}, nil
+}
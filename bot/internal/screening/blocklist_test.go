@@ -0,0 +1,58 @@
+package screening
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBlocklistReplace(t *testing.T) {
+	blocklist := NewBlocklist([]string{"bad", "no way"})
+	mask := func(word string) string {
+		return "[" + strings.ToUpper(word) + "]"
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single word", "this is bad", "this is [BAD]"},
+		{"adjacent repeats", "bad bad bad", "[BAD] [BAD] [BAD]"},
+		{"repeats around punctuation", "bad,bad.bad!", "[BAD],[BAD].[BAD]!"},
+		{"inside a longer word", "badge and abad", "badge and abad"},
+		{"longer word before a match", "badbad bad", "badbad [BAD]"},
+		{"mixed case", "Bad BAD", "[BAD] [BAD]"},
+		{"phrase across whitespace", "no  way", "[NO  WAY]"},
+		{"non-latin letters around", "ébad bad", "ébad [BAD]"},
+		{"empty text", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blocklist.Replace(tt.text, mask); got != tt.want {
+				t.Errorf("Replace(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlocklistMatch(t *testing.T) {
+	blocklist := NewBlocklist([]string{"ass"})
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"you ass", "ass"},
+		{"ass ass", "ass"},
+		{"first class", ""},
+		{"classass ass", "ass"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := blocklist.Match(tt.text); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package screening
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Ways the output filter can deal with what it finds.
+const (
+	OutputNeutralize = "neutralize"
+	OutputStrip      = "strip"
+)
+
+var (
+	invitePattern      = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?(?:discord(?:app)?\.com/invite|discord\.(?:gg|io|me|li)|dsc\.gg)/[a-z0-9-]+`)
+	massMentionPattern = regexp.MustCompile(`@(everyone|here)\b`)
+)
+
+// OutputFilter cleans up AI replies before they are posted. Mentions are
+// also kept from pinging by the allowed mentions of the message, so this
+// only changes how the reply reads.
+type OutputFilter struct {
+	Invites      bool
+	MassMentions bool
+	Blocklist    *Blocklist
+
+	// Mode is OutputNeutralize or OutputStrip.
+	Mode string
+}
+
+func (f OutputFilter) Apply(text string) string {
+	strip := f.Mode == OutputStrip
+
+	if f.Invites {
+		text = invitePattern.ReplaceAllStringFunc(text, func(invite string) string {
+			if strip {
+				return "[invite removed]"
+			}
+			// Defanged links are neither clickable nor embedded.
+			return strings.ReplaceAll(invite, ".", "[.]")
+		})
+	}
+
+	if f.MassMentions {
+		text = massMentionPattern.ReplaceAllStringFunc(text, func(mention string) string {
+			if strip {
+				return ""
+			}
+			return "@\u200b" + mention[1:]
+		})
+	}
+
+	if f.Blocklist != nil {
+		text = f.Blocklist.Replace(text, func(word string) string {
+			if strip {
+				return "[removed]"
+			}
+
+			first, size := utf8.DecodeRuneInString(word)
+			return string(first) + strings.Repeat(`\*`, utf8.RuneCountInString(word[size:]))
+		})
+	}
+
+	return text
+}
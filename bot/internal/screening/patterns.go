@@ -0,0 +1,86 @@
+package screening
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+// MaxPatternLength keeps admin-defined patterns readable. Go's regular
+// expressions run in linear time, so length is the only concern.
+const MaxPatternLength = 200
+
+// injectionPatterns catch the usual attempts to pass a message off as
+// instructions to the model.
+var injectionPatterns = []string{
+	`\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|your|system)\b.{0,20}\b(instructions?|prompts?|rules|messages?|directives)\b`,
+	`\bsystem\s*(message|prompt|instructions?)\s*:`,
+	`\b(new|updated|real)\s+(instructions?|system prompt)\s*:`,
+	`\byou\s+are\s+now\s+(in\s+)?(dan|developer mode|jailbroken|unrestricted)\b`,
+	`\b(reveal|print|show|repeat)\b.{0,20}\b(system prompt|system message|your instructions|hidden instructions)\b`,
+	`</?(system|instructions?|history|message)>`,
+	`\[/?(inst|system)\]`,
+}
+
+// Patterns blocks messages matching any of its regular expressions.
+type Patterns struct {
+	name     string
+	patterns []*regexp.Regexp
+}
+
+// NewPatterns compiles the expressions, which match case-insensitively
+// against the normalized message.
+func NewPatterns(name string, expressions []string) (*Patterns, error) {
+	patterns := &Patterns{name: name}
+
+	for _, expression := range expressions {
+		pattern, err := CompilePattern(expression)
+		if err != nil {
+			return nil, err
+		}
+
+		patterns.patterns = append(patterns.patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// NewInjectionFilter returns the built-in prompt-injection patterns.
+func NewInjectionFilter() *Patterns {
+	patterns, err := NewPatterns("injection filter", injectionPatterns)
+	if err != nil {
+		panic(err)
+	}
+
+	return patterns
+}
+
+// CompilePattern validates an admin-defined expression.
+func CompilePattern(expression string) (*regexp.Regexp, error) {
+	if len(expression) > MaxPatternLength {
+		return nil, fmt.Errorf("patterns can be at most %v characters long", MaxPatternLength)
+	}
+
+	pattern, err := regexp.Compile("(?i)" + expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern '%v': %v", expression, err)
+	}
+
+	return pattern, nil
+}
+
+func (p *Patterns) Name() string {
+	return p.name
+}
+
+func (p *Patterns) Check(ctx context.Context, text string) (Verdict, error) {
+	normalized := Normalize(text)
+
+	for _, pattern := range p.patterns {
+		if match := pattern.FindString(normalized); match != "" {
+			return Verdict{Blocked: true, Reason: fmt.Sprintf("matches '%v'", match)}, nil
+		}
+	}
+
+	return Verdict{}, nil
+}
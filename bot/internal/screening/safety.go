@@ -0,0 +1,103 @@
+package screening
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bot/internal/metrics"
+
+	"google.golang.org/genai"
+)
+
+// Safety thresholds admins can choose from, from strictest to most lenient.
+const (
+	SafetyLow    = "low"
+	SafetyMedium = "medium"
+	SafetyHigh   = "high"
+)
+
+var safetyThresholds = map[string]genai.HarmBlockThreshold{
+	SafetyLow:    genai.HarmBlockThresholdBlockLowAndAbove,
+	SafetyMedium: genai.HarmBlockThresholdBlockMediumAndAbove,
+	SafetyHigh:   genai.HarmBlockThresholdBlockOnlyHigh,
+}
+
+var safetyCategories = []genai.HarmCategory{
+	genai.HarmCategoryHarassment,
+	genai.HarmCategoryHateSpeech,
+	genai.HarmCategorySexuallyExplicit,
+	genai.HarmCategoryDangerousContent,
+}
+
+// ValidSafetyThreshold reports whether the threshold is one of the levels
+// above.
+func ValidSafetyThreshold(threshold string) bool {
+	_, ok := safetyThresholds[threshold]
+	return ok
+}
+
+// SafetyClassifier asks Gemini to classify the message with its safety
+// filters. The message is only classified, the model's answer is discarded.
+type SafetyClassifier struct {
+	Client    *genai.Client
+	Threshold string
+
+	// Usage holds the tokens the last classification used.
+	Usage *genai.GenerateContentResponseUsageMetadata
+}
+
+func (c *SafetyClassifier) Name() string {
+	return "safety classifier"
+}
+
+func (c *SafetyClassifier) Check(ctx context.Context, text string) (Verdict, error) {
+	threshold, ok := safetyThresholds[c.Threshold]
+	if !ok {
+		return Verdict{}, fmt.Errorf("unknown safety threshold '%v'", c.Threshold)
+	}
+
+	settings := make([]*genai.SafetySetting, 0, len(safetyCategories))
+	for _, category := range safetyCategories {
+		settings = append(settings, &genai.SafetySetting{Category: category, Threshold: threshold})
+	}
+
+	start := time.Now()
+	resp, err := c.Client.Models.GenerateContent(ctx, "gemini-2.5-flash-lite", genai.Text(text), &genai.GenerateContentConfig{
+		SystemInstruction: genai.NewContentFromText("Reply with OK.", genai.RoleUser),
+		SafetySettings:    settings,
+		MaxOutputTokens:   1,
+	})
+	metrics.ObserveGemini("safety", start, err)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to classify message: %v", err)
+	}
+	c.Usage = resp.UsageMetadata
+
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return Verdict{Blocked: true, Reason: blockedReason(string(resp.PromptFeedback.BlockReason), resp.PromptFeedback.SafetyRatings)}, nil
+	}
+
+	if len(resp.Candidates) > 0 && resp.Candidates[0].FinishReason == genai.FinishReasonSafety {
+		return Verdict{Blocked: true, Reason: blockedReason("SAFETY", resp.Candidates[0].SafetyRatings)}, nil
+	}
+
+	return Verdict{}, nil
+}
+
+// blockedReason names the categories that caused the block.
+func blockedReason(reason string, ratings []*genai.SafetyRating) string {
+	var categories []string
+	for _, rating := range ratings {
+		if rating.Blocked {
+			categories = append(categories, strings.ToLower(strings.TrimPrefix(string(rating.Category), "HARM_CATEGORY_")))
+		}
+	}
+
+	if len(categories) == 0 {
+		return "flagged by Gemini (" + strings.ToLower(reason) + ")"
+	}
+
+	return "flagged by Gemini for " + strings.Join(categories, ", ")
+}
@@ -0,0 +1,64 @@
+package screening
+
+import (
+	"context"
+	"strings"
+
+	"bot/internal/logging"
+)
+
+// Verdict is the outcome of screening a message. Stage and Reason say which
+// check blocked it and why, for the mod-log.
+type Verdict struct {
+	Blocked bool
+	Stage   string
+	Reason  string
+}
+
+// Stage is one check a message goes through before it reaches the model.
+type Stage interface {
+	Name() string
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// Pipeline runs its stages in order and stops at the first that blocks.
+type Pipeline struct {
+	Stages []Stage
+}
+
+// Screen checks the text against every stage. A stage that fails, such as
+// a classifier that cannot be reached, is skipped so that an outage does not
+// silence the bot.
+func (p Pipeline) Screen(ctx context.Context, text string) Verdict {
+	for _, stage := range p.Stages {
+		verdict, err := stage.Check(ctx, text)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Screening stage failed", "stage", stage.Name(), "error", err)
+			continue
+		}
+
+		if verdict.Blocked {
+			verdict.Stage = stage.Name()
+			return verdict
+		}
+	}
+
+	return Verdict{}
+}
+
+// invisibleCharacters are removed before matching, since they are a common
+// way to slip words past a filter.
+var invisibleCharacters = strings.NewReplacer(
+	"\u00ad", "",
+	"\u200b", "",
+	"\u200c", "",
+	"\u200d", "",
+	"\u2060", "",
+	"\ufeff", "",
+)
+
+// Normalize lowercases the text, removes invisible characters and collapses
+// whitespace.
+func Normalize(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(invisibleCharacters.Replace(text))), " ")
+}
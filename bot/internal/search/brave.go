@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"bot/internal/logging"
+	"bot/internal/structs"
+)
+
+type Brave struct {
+	APIKey string
+}
+
+func NewBrave(apiKey string) *Brave {
+	return &Brave{APIKey: apiKey}
+}
+
+func (b *Brave) Name() string {
+	return "Brave Search"
+}
+
+func (b *Brave) Search(ctx context.Context, query string) ([]structs.SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("count", fmt.Sprint(maxResults*2))
+
+	urlToFetch := baseURL("BRAVE_BASE_URL", "https://api.search.brave.com") + "/res/v1/web/search?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", urlToFetch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Subscription-Token", b.APIKey)
+
+	var fetched structs.FetchedBraveSearch
+	err = fetchJSON(req, "brave", &fetched)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]structs.SearchResult, 0, len(fetched.Web.Results))
+	for _, result := range fetched.Web.Results {
+		snippet := result.Description
+		if len(result.ExtraSnippets) > 0 {
+			snippet += " " + strings.Join(result.ExtraSnippets, " ")
+		}
+
+		results = append(results, structs.SearchResult{
+			Title:   result.Title,
+			URL:     result.URL,
+			Snippet: stripTags(snippet),
+		})
+	}
+
+	results = TrimResults(results)
+	logging.FromContext(ctx).Info("Search finished", "provider", b.Name(), "results", len(results), logging.Content("query", query))
+
+	return results, nil
+}
+
+// stripTags removes the <strong> highlighting Brave adds around matched words.
+func stripTags(snippet string) string {
+	return strings.NewReplacer("<strong>", "", "</strong>", "").Replace(snippet)
+}
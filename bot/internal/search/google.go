@@ -0,0 +1,87 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"bot/internal/logging"
+	"bot/internal/metrics"
+	"bot/internal/structs"
+
+	"google.golang.org/genai"
+)
+
+// Google searches with Gemini's built-in Google Search grounding. The API
+// does not allow the search tool next to function declarations, so the search
+// runs as a separate grounded request whose sources become the results.
+type Google struct {
+	Client *genai.Client
+	Model  string
+
+	// Usage holds the tokens the last search used, since it is paid for with
+	// the guild's key like the answer itself.
+	Usage *genai.GenerateContentResponseUsageMetadata
+}
+
+func NewGoogle(client *genai.Client, model string) *Google {
+	return &Google{Client: client, Model: model}
+}
+
+func (g *Google) Name() string {
+	return "Google Search"
+}
+
+func (g *Google) Search(ctx context.Context, query string) ([]structs.SearchResult, error) {
+	config := &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{
+			{GoogleSearch: &genai.GoogleSearch{}},
+		},
+	}
+
+	start := time.Now()
+	resp, err := g.Client.Models.GenerateContent(ctx, g.Model, genai.Text("Search the web and summarize what you find about: "+query), config)
+	metrics.ObserveGemini("search", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search with google: %v", err)
+	}
+	g.Usage = resp.UsageMetadata
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].GroundingMetadata == nil {
+		return nil, fmt.Errorf("google search returned no sources")
+	}
+
+	metadata := resp.Candidates[0].GroundingMetadata
+
+	// Each source's snippet is made of the parts of the answer it supports.
+	snippets := make([][]string, len(metadata.GroundingChunks))
+	for _, support := range metadata.GroundingSupports {
+		if support.Segment == nil {
+			continue
+		}
+		for _, index := range support.GroundingChunkIndices {
+			if int(index) < len(snippets) {
+				snippets[index] = append(snippets[index], support.Segment.Text)
+			}
+		}
+	}
+
+	results := make([]structs.SearchResult, 0, len(metadata.GroundingChunks))
+	for idx, chunk := range metadata.GroundingChunks {
+		if chunk.Web == nil {
+			continue
+		}
+
+		results = append(results, structs.SearchResult{
+			Title:   chunk.Web.Title,
+			URL:     chunk.Web.URI,
+			Snippet: strings.Join(snippets[idx], " "),
+		})
+	}
+
+	results = TrimResults(results)
+	logging.FromContext(ctx).Info("Search finished", "provider", g.Name(), "results", len(results), logging.Content("query", query))
+
+	return results, nil
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"bot/internal/logging"
+	"bot/internal/structs"
+)
+
+// Names of the search providers a guild can choose from.
+const (
+	VyntrProvider   = "vyntr"
+	SearXNGProvider = "searxng"
+	BraveProvider   = "brave"
+	GoogleProvider  = "google"
+)
+
+// DefaultProvider is used by guilds that have not chosen a provider, which
+// keeps the Vyntr key they already set up working.
+const DefaultProvider = VyntrProvider
+
+// Provider searches the web and returns the results ranked best first.
+type Provider interface {
+	Name() string
+	Search(ctx context.Context, query string) ([]structs.SearchResult, error)
+}
+
+const (
+	// maxResults is how many results are given to the model.
+	maxResults = 5
+
+	// resultBudget caps the snippets of all results together, at roughly four
+	// characters per token, so a search costs at most about 1500 tokens.
+	resultBudget = 6000
+
+	// maxSnippetLength keeps one long page from using the whole budget.
+	maxSnippetLength = 1200
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// baseURL returns the URL in the environment variable, so a provider can be
+// pointed at a local stub or a self-hosted instance, or fallback otherwise.
+func baseURL(variable string, fallback string) string {
+	if value := os.Getenv(variable); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+
+	return fallback
+}
+
+// fetchJSON sends the request and decodes a successful JSON response into
+// target.
+func fetchJSON(req *http.Request, provider string, target any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		logging.FromContext(req.Context()).Error("Error while fetching search results", "provider", provider, "error", err)
+		return fmt.Errorf("failed to fetch search results: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		logging.FromContext(req.Context()).Error("Search request failed", "provider", provider, "status", resp.StatusCode, "body", string(body))
+		return fmt.Errorf("failed to search with %v: status %v", provider, resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(target)
+	if err != nil {
+		return fmt.Errorf("failed to decode %v search results: %v", provider, err)
+	}
+
+	return nil
+}
+
+// TrimResults keeps the first results that have a URL, in the order the
+// search engine ranked them, dropping duplicate URLs and shortening snippets so
+// that together they fit the result budget.
+func TrimResults(results []structs.SearchResult) []structs.SearchResult {
+	trimmed := make([]structs.SearchResult, 0, maxResults)
+	seen := make(map[string]bool)
+	remaining := resultBudget
+
+	for _, result := range results {
+		if len(trimmed) == maxResults || remaining <= 0 {
+			break
+		}
+
+		result.URL = strings.TrimSpace(result.URL)
+		if result.URL == "" || seen[result.URL] {
+			continue
+		}
+		seen[result.URL] = true
+
+		limit := min(maxSnippetLength, remaining)
+		result.Title = strings.TrimSpace(result.Title)
+		result.Snippet = truncateSnippet(strings.Join(strings.Fields(result.Snippet), " "), limit)
+		remaining -= len(result.Snippet)
+
+		trimmed = append(trimmed, result)
+	}
+
+	return trimmed
+}
+
+// truncateSnippet shortens a snippet to at most limit bytes, cutting at a word
+// boundary where possible.
+func truncateSnippet(snippet string, limit int) string {
+	if len(snippet) <= limit {
+		return snippet
+	}
+
+	cut := strings.ToValidUTF8(snippet[:limit], "")
+	if space := strings.LastIndex(cut, " "); space > limit/2 {
+		cut = cut[:space]
+	}
+
+	return cut + "…"
+}
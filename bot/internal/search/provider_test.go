@@ -0,0 +1,86 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"bot/internal/structs"
+)
+
+func TestTrimResults(t *testing.T) {
+	t.Run("drops results without URL and duplicates", func(t *testing.T) {
+		trimmed := TrimResults([]structs.SearchResult{
+			{Title: " First ", URL: "https://a.example", Snippet: "one"},
+			{Title: "No URL", URL: "  ", Snippet: "skipped"},
+			{Title: "Duplicate", URL: " https://a.example ", Snippet: "skipped"},
+			{Title: "Second", URL: "https://b.example", Snippet: "two  \n words"},
+		})
+
+		want := []structs.SearchResult{
+			{Title: "First", URL: "https://a.example", Snippet: "one"},
+			{Title: "Second", URL: "https://b.example", Snippet: "two words"},
+		}
+		if len(trimmed) != len(want) {
+			t.Fatalf("got %v results, want %v: %+v", len(trimmed), len(want), trimmed)
+		}
+		for idx := range want {
+			if trimmed[idx] != want[idx] {
+				t.Errorf("result %v = %+v, want %+v", idx, trimmed[idx], want[idx])
+			}
+		}
+	})
+
+	t.Run("keeps at most maxResults in order", func(t *testing.T) {
+		var results []structs.SearchResult
+		for idx := range maxResults + 3 {
+			results = append(results, structs.SearchResult{URL: fmt.Sprintf("https://%v.example", idx)})
+		}
+
+		trimmed := TrimResults(results)
+		if len(trimmed) != maxResults {
+			t.Fatalf("got %v results, want %v", len(trimmed), maxResults)
+		}
+		for idx, result := range trimmed {
+			if want := fmt.Sprintf("https://%v.example", idx); result.URL != want {
+				t.Errorf("result %v has URL %q, want %q", idx, result.URL, want)
+			}
+		}
+	})
+
+	t.Run("shortens snippets to the budget", func(t *testing.T) {
+		long := strings.Repeat("word ", maxSnippetLength)
+
+		var results []structs.SearchResult
+		for idx := range maxResults {
+			results = append(results, structs.SearchResult{URL: fmt.Sprintf("https://%v.example", idx), Snippet: long})
+		}
+
+		total := 0
+		for _, result := range TrimResults(results) {
+			length := len(strings.TrimSuffix(result.Snippet, "…"))
+			if length > maxSnippetLength {
+				t.Errorf("snippet of %v is %v bytes, want at most %v", result.URL, length, maxSnippetLength)
+			}
+			if !strings.HasSuffix(result.Snippet, "…") {
+				t.Errorf("snippet of %v was not marked as shortened", result.URL)
+			}
+			total += length
+		}
+
+		if total > resultBudget {
+			t.Errorf("snippets total %v bytes, want at most %v", total, resultBudget)
+		}
+	})
+
+	t.Run("cuts snippets on valid UTF-8", func(t *testing.T) {
+		trimmed := TrimResults([]structs.SearchResult{
+			{URL: "https://a.example", Snippet: strings.Repeat("é", maxSnippetLength)},
+		})
+
+		if snippet := trimmed[0].Snippet; !strings.HasSuffix(snippet, "…") || !utf8.ValidString(snippet) {
+			t.Errorf("snippet was not cut cleanly: %q", snippet[len(snippet)-10:])
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"bot/internal/logging"
+	"bot/internal/structs"
+)
+
+// SearXNG queries a self-hosted SearXNG instance, which needs no API key.
+// The instance is set by the operator with SEARXNG_BASE_URL and must have the
+// JSON output format enabled.
+type SearXNG struct {
+	BaseURL string
+}
+
+func NewSearXNG() (*SearXNG, error) {
+	if os.Getenv("SEARXNG_BASE_URL") == "" {
+		return nil, fmt.Errorf("SearXNG is not available on this bot, SEARXNG_BASE_URL is not set")
+	}
+
+	return &SearXNG{BaseURL: baseURL("SEARXNG_BASE_URL", "")}, nil
+}
+
+func (x *SearXNG) Name() string {
+	return "SearXNG"
+}
+
+func (x *SearXNG) Search(ctx context.Context, query string) ([]structs.SearchResult, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", x.BaseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	var fetched structs.FetchedSearXNGSearch
+	err = fetchJSON(req, "searxng", &fetched)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]structs.SearchResult, 0, len(fetched.Results))
+	for _, result := range fetched.Results {
+		results = append(results, structs.SearchResult{
+			Title:   result.Title,
+			URL:     result.URL,
+			Snippet: result.Content,
+		})
+	}
+
+	results = TrimResults(results)
+	logging.FromContext(ctx).Info("Search finished", "provider", x.Name(), "results", len(results), logging.Content("query", query))
+
+	return results, nil
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"bot/internal/logging"
+	"bot/internal/structs"
+)
+
+type Vyntr struct {
+	APIKey string
+}
+
+func NewVyntr(apiKey string) *Vyntr {
+	return &Vyntr{APIKey: apiKey}
+}
+
+func (v *Vyntr) Name() string {
+	return "Vyntr"
+}
+
+func (v *Vyntr) Search(ctx context.Context, query string) ([]structs.SearchResult, error) {
+	urlToFetch := baseURL("VYNTR_BASE_URL", "https://vyntr.com") + "/api/v1/search?q=" + url.QueryEscape(query)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", urlToFetch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+v.APIKey)
+
+	var fetched structs.FetchedVyntrSearch
+	err = fetchJSON(req, "vyntr", &fetched)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []structs.SearchResult
+
+	if fetched.Bliptext != nil && fetched.Bliptext.Summary != "" {
+		results = append(results, structs.SearchResult{
+			Title:   fetched.Bliptext.Title,
+			URL:     fetched.Bliptext.URL,
+			Snippet: fetched.Bliptext.Summary,
+		})
+	}
+
+	for _, result := range fetched.Web {
+		results = append(results, structs.SearchResult{
+			Title:   result.Title,
+			URL:     result.URL,
+			Snippet: result.Preview,
+		})
+	}
+
+	results = TrimResults(results)
+	logging.FromContext(ctx).Info("Search finished", "provider", v.Name(), "results", len(results), logging.Content("query", query))
+
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bot/internal/structs"
+)
+
+func TestVyntrSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/v1/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("q") {
+		case "go language":
+			w.Write([]byte(`{
+				"bliptext": {"title": "Go", "summary": "A programming  language.", "url": "https://bliptext.example/go"},
+				"web": [
+					{"title": "The Go Programming Language", "url": "https://go.dev", "preview": "Build simple, secure, scalable systems."},
+					{"title": "Duplicate", "url": "https://go.dev", "preview": "Dropped."},
+					{"title": "No URL", "url": "", "preview": "Dropped."}
+				]
+			}`))
+		case "empty summary":
+			w.Write([]byte(`{"bliptext": {"title": "Empty", "summary": "", "url": "https://bliptext.example/empty"}, "web": []}`))
+		default:
+			w.Write([]byte(`not json`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("VYNTR_BASE_URL", server.URL+"/")
+
+	tests := []struct {
+		name    string
+		apiKey  string
+		query   string
+		want    []structs.SearchResult
+		wantErr string
+	}{
+		{
+			name:   "summary first, then web results",
+			apiKey: "test-key",
+			query:  "go language",
+			want: []structs.SearchResult{
+				{Title: "Go", URL: "https://bliptext.example/go", Snippet: "A programming language."},
+				{Title: "The Go Programming Language", URL: "https://go.dev", Snippet: "Build simple, secure, scalable systems."},
+			},
+		},
+		{
+			name:   "empty summary is skipped",
+			apiKey: "test-key",
+			query:  "empty summary",
+			want:   []structs.SearchResult{},
+		},
+		{
+			name:    "invalid response",
+			apiKey:  "test-key",
+			query:   "broken",
+			wantErr: "failed to decode vyntr search results",
+		},
+		{
+			name:    "rejected key",
+			apiKey:  "wrong-key",
+			query:   "go language",
+			wantErr: "status 401",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVyntr(tt.apiKey).Search(context.Background(), tt.query)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Search() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() returned an error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() returned %v results, want %v: %+v", len(got), len(tt.want), got)
+			}
+			for idx := range tt.want {
+				if got[idx] != tt.want[idx] {
+					t.Errorf("result %v = %+v, want %+v", idx, got[idx], tt.want[idx])
+				}
+			}
+		})
+	}
+}
@@ -2,69 +2,57 @@ package serverping
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"strconv"
+	"time"
 
-	"github.com/joho/godotenv"
+	"bot/internal/health"
 )
 
-func SendUptime(durationSinceStart int64) {
-	fmt.Println("Attempting to ping server of uptime: " + strconv.FormatInt(durationSinceStart, 10))
+var httpClient = &http.Client{Timeout: 10 * time.Second}
 
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error while loading .env file:", err)
-	}
-
-	type UptimeReport struct {
-		Uptime int64  `json:"uptime"`
-		Secret string `json:"secret"`
-	}
+// uptimeReport keeps the uptime and secret fields the website reads, with
+// the health report alongside them.
+type uptimeReport struct {
+	health.Report
+	Secret string `json:"secret"`
+}
 
-	report := UptimeReport{
-		Uptime: durationSinceStart,
-		Secret: os.Getenv("PING_SECRET"),
+// SendUptime posts the health report to the server that shows the bot's
+// uptime.
+func SendUptime(ctx context.Context, serverURL string, secret string, report health.Report) error {
+	jsonReport, err := json.Marshal(uptimeReport{Report: report, Secret: secret})
+	if err != nil {
+		return fmt.Errorf("failed to create report: %v", err)
 	}
 
-	jsonReport, err := json.Marshal(report)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL, bytes.NewReader(jsonReport))
 	if err != nil {
-		fmt.Println("Error while creating report:", err)
-		return
+		return fmt.Errorf("failed to create request: %v", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.Post(
-		os.Getenv("SERVER_TO_PING"),
-		"application/json",
-		bytes.NewBuffer(jsonReport),
-	)
-
+	resp, err := httpClient.Do(req)
 	if err != nil {
-		fmt.Println("Error while sending uptime:", err)
-		return
+		return fmt.Errorf("failed to send uptime: %v", err)
 	}
 
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		bodyString := string(bodyBytes)
-
-		fmt.Println("Ping failed:", bodyString)
-
-		return
+	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error while reading response body:", err)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server answered with status %v: %v", resp.StatusCode, string(bodyBytes))
 	}
 
-	bodyString := string(bodyBytes)
+	slog.Debug("Reported uptime", "status", report.Status, "response", string(bodyBytes))
 
-	fmt.Println("Successfully pinged server and reported uptime:", bodyString)
+	return nil
 }
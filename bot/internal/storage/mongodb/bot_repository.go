@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"log/slog"
 	"os"
 
+	"bot/internal/content"
 	"bot/internal/decryption"
 	"bot/internal/structs"
 
@@ -34,17 +36,10 @@ func (r *BotRepository) FetchConversations(guildID string) ([]structs.Conversati
 		return nil, err
 	}
 
-	fmt.Println("Conversations fetched successfully:", settings.Conversations)
-
 	return settings.Conversations, nil
 }
 
-func (r *BotRepository) AddConversations(guildID string, userConv structs.User, botConv string) error {
-	var conversation structs.Conversation = structs.Conversation{
-		User: userConv,
-		Bot:  botConv,
-	}
-
+func (r *BotRepository) AddConversations(guildID string, conversation structs.Conversation) error {
 	filter := bson.M{"server_id": guildID}
 	update := bson.M{
 		"$push": bson.M{
@@ -57,13 +52,90 @@ func (r *BotRepository) AddConversations(guildID string, userConv structs.User,
 
 	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
 	if err != nil {
-		fmt.Println("Error while adding to conversation history:", err)
+		slog.Error("Error while adding to conversation history", "error", err)
 		return err
 	}
 
 	return nil
 }
 
+func (r *BotRepository) FetchConversation(guildID string, conversationID string) (*structs.Conversation, error) {
+	conversations, err := r.FetchConversations(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conversation := range conversations {
+		if conversation.ID == conversationID {
+			return &conversation, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ReplaceConversationReply swaps the bot's answer of a stored conversation for
+// a regenerated one and forgets any follow-up messages of the old answer.
+func (r *BotRepository) ReplaceConversationReply(guildID string, conversationID string, botConv string) error {
+	filter := bson.M{"server_id": guildID, "conversations.id": conversationID}
+	update := bson.M{
+		"$set": bson.M{
+			"conversations.$.bot":        botConv,
+			"conversations.$.follow_ups": []string{},
+		},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to replace conversation reply: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("conversation %s not found", conversationID)
+	}
+
+	return nil
+}
+
+// ExtendConversationReply stores the continuation of an answer, along with the
+// ID of the message it was posted in.
+func (r *BotRepository) ExtendConversationReply(guildID string, conversationID string, botConv string, followUpID string) error {
+	filter := bson.M{"server_id": guildID, "conversations.id": conversationID}
+	update := bson.M{
+		"$set": bson.M{
+			"conversations.$.bot": botConv,
+		},
+		"$push": bson.M{
+			"conversations.$.follow_ups": followUpID,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to extend conversation reply: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("conversation %s not found", conversationID)
+	}
+
+	return nil
+}
+
+func (r *BotRepository) RemoveConversation(guildID string, conversationID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$pull": bson.M{
+			"conversations": bson.M{"id": conversationID},
+		},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to remove conversation: %w", err)
+	}
+
+	return nil
+}
+
 func (r *BotRepository) FetchNickname(guildID string) (string, error) {
 	var settings structs.Bot
 	filter := bson.M{"server_id": guildID}
@@ -75,8 +147,6 @@ func (r *BotRepository) FetchNickname(guildID string) (string, error) {
 		return "", err
 	}
 
-	fmt.Println("Nickname fetched successfully:", settings.Name)
-
 	return settings.Name, nil
 }
 
@@ -91,8 +161,6 @@ func (r *BotRepository) FetchBotPersona(guildID string) (string, error) {
 		return "", err
 	}
 
-	fmt.Println("Persona fetched successfully:", settings.Persona)
-
 	return settings.Persona, nil
 }
 
@@ -108,22 +176,9 @@ func (r *BotRepository) FetchApiKey(serverId string) (string, error) {
 		return "", fmt.Errorf("mongo find error: %w", err)
 	}
 
-	keyHex := os.Getenv("CRYPTO_SECRET_KEY")
-	key, err := hex.DecodeString(keyHex)
-	if err != nil {
-		return "", fmt.Errorf("invalid AES key hex: %w", err)
-	}
-	if len(key) != 32 {
-		return "", fmt.Errorf("AES key must be 32 bytes, got %d", len(key))
-	}
-
-	plain, err := decryption.DecryptAES256CBC(
-		fetchedBot.GoogleAIAPI.EncryptedData,
-		fetchedBot.GoogleAIAPI.IV,
-		key,
-	)
+	plain, err := decryptAPIKey(fetchedBot.GoogleAIAPI, "Google AI")
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt Google AI API key: %w", err)
+		return "", err
 	}
 
 	return plain, nil
@@ -141,28 +196,35 @@ func (r *BotRepository) FetchWeatherApiKey(serverId string) (string, error) {
 		return "", fmt.Errorf("mongo find error: %w", err)
 	}
 
-	keyHex := os.Getenv("CRYPTO_SECRET_KEY")
-	key, err := hex.DecodeString(keyHex)
+	plain, err := decryptAPIKey(fetchedBot.OpenWeatherMapAPI, "OpenWeatherMap")
 	if err != nil {
-		return "", fmt.Errorf("invalid AES key hex: %w", err)
+		return "", err
 	}
-	if len(key) != 32 {
-		return "", fmt.Errorf("AES key must be 32 bytes, got %d", len(key))
+
+	return plain, nil
+}
+
+func (r *BotRepository) FetchVyntrApiKey(serverId string) (string, error) {
+	var fetchedBot structs.Bot
+
+	filter := bson.M{"server_id": serverId}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&fetchedBot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", fmt.Errorf("no document found for guild %s", serverId)
+		}
+		return "", fmt.Errorf("mongo find error: %w", err)
 	}
 
-	plain, err := decryption.DecryptAES256CBC(
-		fetchedBot.OpenWeatherMapAPI.EncryptedData,
-		fetchedBot.OpenWeatherMapAPI.IV,
-		key,
-	)
+	plain, err := decryptAPIKey(fetchedBot.VyntrAPI, "Vyntr")
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt OpenWeatherMap API key: %w", err)
+		return "", err
 	}
 
 	return plain, nil
 }
 
-func (r *BotRepository) FetchVyntrApiKey(serverId string) (string, error) {
+func (r *BotRepository) FetchBraveApiKey(serverId string) (string, error) {
 	var fetchedBot structs.Bot
 
 	filter := bson.M{"server_id": serverId}
@@ -174,8 +236,25 @@ func (r *BotRepository) FetchVyntrApiKey(serverId string) (string, error) {
 		return "", fmt.Errorf("mongo find error: %w", err)
 	}
 
-	keyHex := os.Getenv("CRYPTO_SECRET_KEY")
-	key, err := hex.DecodeString(keyHex)
+	if fetchedBot.BraveAPI.EncryptedData == "" {
+		return "", fmt.Errorf("no Brave Search API key is set for this server")
+	}
+
+	plain, err := decryptAPIKey(fetchedBot.BraveAPI, "Brave Search")
+	if err != nil {
+		return "", err
+	}
+	if plain == "" {
+		return "", fmt.Errorf("no Brave Search API key is set for this server")
+	}
+
+	return plain, nil
+}
+
+// decryptAPIKey decrypts an API key stored for a guild with the key from
+// CRYPTO_SECRET_KEY. The name is only used in errors.
+func decryptAPIKey(api structs.EncryptedAPI, name string) (string, error) {
+	key, err := hex.DecodeString(os.Getenv("CRYPTO_SECRET_KEY"))
 	if err != nil {
 		return "", fmt.Errorf("invalid AES key hex: %w", err)
 	}
@@ -183,14 +262,420 @@ func (r *BotRepository) FetchVyntrApiKey(serverId string) (string, error) {
 		return "", fmt.Errorf("AES key must be 32 bytes, got %d", len(key))
 	}
 
-	plain, err := decryption.DecryptAES256CBC(
-		fetchedBot.VyntrAPI.EncryptedData,
-		fetchedBot.VyntrAPI.IV,
-		key,
-	)
+	plain, err := decryption.DecryptAES256CBC(api.EncryptedData, api.IV, key)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt OpenWeatherMap API key: %w", err)
+		return "", fmt.Errorf("failed to decrypt %v API key: %w", name, err)
 	}
 
 	return plain, nil
 }
+
+// FetchSearchProvider returns the name of the web search provider the guild
+// chose, or an empty string when it has not chosen one.
+func (r *BotRepository) FetchSearchProvider(guildID string) (string, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return settings.SearchProvider, nil
+}
+
+func (r *BotRepository) SetSearchProvider(guildID string, provider string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"search_provider": provider},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update search provider: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+// FetchPrefetchLinks reports whether links in messages should be read before
+// the model is asked to answer.
+func (r *BotRepository) FetchPrefetchLinks(guildID string) (bool, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return settings.PrefetchLinks, nil
+}
+
+func (r *BotRepository) SetPrefetchLinks(guildID string, enabled bool) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"prefetch_links": enabled},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update link settings: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+// FetchModLogChannel returns the channel moderation actions are logged to, or
+// an empty string when none is set.
+func (r *BotRepository) FetchModLogChannel(guildID string) (string, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return settings.ModLogChannel, nil
+}
+
+func (r *BotRepository) SetModLogChannel(guildID string, channelID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"mod_log_channel": channelID},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update mod-log channel: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+// FetchConversationsPage returns up to limit conversations starting at offset,
+// newest first, along with the total number of stored conversations.
+func (r *BotRepository) FetchConversationsPage(guildID string, offset int, limit int) ([]structs.Conversation, int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"server_id": guildID}}},
+		{{Key: "$project", Value: bson.M{
+			"total":         bson.M{"$size": bson.M{"$ifNull": bson.A{"$conversations", bson.A{}}}},
+			"conversations": bson.M{"$slice": bson.A{bson.M{"$ifNull": bson.A{"$conversations", bson.A{}}}, offset, limit}},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch conversations page: %w", err)
+	}
+	defer cursor.Close(context.TODO())
+
+	var page struct {
+		Total         int                    `bson:"total"`
+		Conversations []structs.Conversation `bson:"conversations"`
+	}
+
+	if !cursor.Next(context.TODO()) {
+		return nil, 0, cursor.Err()
+	}
+	if err := cursor.Decode(&page); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode conversations page: %w", err)
+	}
+
+	return page.Conversations, page.Total, nil
+}
+
+func (r *BotRepository) ClearConversations(guildID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"conversations": []structs.Conversation{}},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to clear conversations: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveUserConversations removes every turn sent by a user. Turns stored
+// before user IDs were recorded cannot be attributed safely, as display names
+// can be copied, so they are left to ClearLegacyConversations.
+func (r *BotRepository) RemoveUserConversations(guildID string, userID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$pull": bson.M{"conversations": bson.M{"user.id": userID}},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to remove user conversations: %w", err)
+	}
+
+	return nil
+}
+
+// ClearLegacyConversations removes the turns stored before user IDs were
+// recorded, which no member can remove on their own.
+func (r *BotRepository) ClearLegacyConversations(guildID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$pull": bson.M{"conversations": bson.M{"user.id": bson.M{"$exists": false}}},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to clear legacy conversations: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchPolicy(guildID string) (structs.GuildPolicy, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.GuildPolicy{}, nil
+		}
+		return structs.GuildPolicy{}, err
+	}
+
+	return settings.Policy, nil
+}
+
+// SetPolicyEntry adds a value to or removes it from one of the policy lists,
+// such as "allowed_roles".
+func (r *BotRepository) SetPolicyEntry(guildID string, list string, value string, present bool) error {
+	operator := "$pull"
+	if present {
+		operator = "$addToSet"
+	}
+
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		operator: bson.M{"policy." + list: value},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+func (r *BotRepository) ResetPolicy(guildID string) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$unset": bson.M{"policy": ""},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to reset policy: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchContentPolicy(guildID string) (structs.ContentPolicy, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.ContentPolicy{}, nil
+		}
+		return structs.ContentPolicy{}, err
+	}
+
+	return settings.ContentPolicy, nil
+}
+
+// SetCategoryMode moves an image category into the content policy list of
+// the mode and out of the others.
+func (r *BotRepository) SetCategoryMode(guildID string, category string, mode string) error {
+	lists := map[string]string{
+		content.ModeEnabled:  "content_policy.enabled_categories",
+		content.ModeNSFWOnly: "content_policy.nsfw_only_categories",
+		content.ModeDisabled: "content_policy.disabled_categories",
+	}
+
+	list, ok := lists[mode]
+	if !ok {
+		return fmt.Errorf("unknown mode '%v'", mode)
+	}
+
+	pull := bson.M{}
+	for other, field := range lists {
+		if other != mode {
+			pull[field] = category
+		}
+	}
+
+	update := bson.M{
+		"$addToSet": bson.M{list: category},
+		"$pull":     pull,
+	}
+
+	filter := bson.M{"server_id": guildID}
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update content policy: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchImageGeneration(guildID string) (structs.ImageGeneration, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.ImageGeneration{}, nil
+		}
+		return structs.ImageGeneration{}, err
+	}
+
+	if settings.ImageGeneration.DailyLimit <= 0 {
+		settings.ImageGeneration.DailyLimit = structs.DefaultImageDailyLimit
+	}
+
+	return settings.ImageGeneration, nil
+}
+
+func (r *BotRepository) SetImageGeneration(guildID string, imageGeneration structs.ImageGeneration) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"image_generation": imageGeneration},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update image generation settings: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchInputScreening(guildID string) (structs.InputScreening, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.InputScreening{}, nil
+		}
+		return structs.InputScreening{}, err
+	}
+
+	return settings.InputScreening, nil
+}
+
+func (r *BotRepository) SetInputScreening(guildID string, inputScreening structs.InputScreening) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"input_screening": inputScreening},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update screening settings: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchOutputFilter(guildID string) (structs.OutputFilter, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.OutputFilter{}, nil
+		}
+		return structs.OutputFilter{}, err
+	}
+
+	return settings.OutputFilter, nil
+}
+
+func (r *BotRepository) SetOutputFilter(guildID string, outputFilter structs.OutputFilter) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"output_filter": outputFilter},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update output filter: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
+
+func (r *BotRepository) FetchUsageBudget(guildID string) (structs.UsageBudget, error) {
+	var settings structs.Bot
+	filter := bson.M{"server_id": guildID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return structs.UsageBudget{}, nil
+		}
+		return structs.UsageBudget{}, err
+	}
+
+	return settings.UsageBudget, nil
+}
+
+func (r *BotRepository) SetUsageBudget(guildID string, usageBudget structs.UsageBudget) error {
+	filter := bson.M{"server_id": guildID}
+	update := bson.M{
+		"$set": bson.M{"usage_budget": usageBudget},
+	}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update usage budget: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no bot is set up for this server")
+	}
+
+	return nil
+}
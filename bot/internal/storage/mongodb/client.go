@@ -2,9 +2,11 @@ package mongodb
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"os"
 
+	"bot/internal/metrics"
+
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
@@ -13,11 +15,11 @@ import (
 func ConnectToMongo() *mongo.Client {
 	var connectionString = os.Getenv("MONGODB_CONNECTION_STRING")
 	if connectionString == "" {
-		fmt.Println("MongoDB connection string not set.")
+		slog.Warn("MongoDB connection string not set")
 	}
 
 	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(connectionString).SetServerAPIOptions(serverAPI)
+	opts := options.Client().ApplyURI(connectionString).SetServerAPIOptions(serverAPI).SetMonitor(metrics.MongoMonitor())
 
 	client, err := mongo.Connect(opts)
 	if err != nil {
@@ -27,7 +29,7 @@ func ConnectToMongo() *mongo.Client {
 	if err := client.Ping(context.TODO(), readpref.Primary()); err != nil {
 		panic(err)
 	}
-	fmt.Println("Pinged your deployment. You successfully connected to MongoDB!")
+	slog.Info("Connected to MongoDB")
 
 	return client
 }
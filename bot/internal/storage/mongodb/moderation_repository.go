@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bot/internal/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type ModerationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewModerationRepository(db *mongo.Database) *ModerationRepository {
+	return &ModerationRepository{
+		collection: db.Collection("moderation_actions"),
+	}
+}
+
+func (r *ModerationRepository) Create(action structs.ModerationAction) (structs.ModerationAction, error) {
+	action.Status = structs.ModerationPending
+
+	result, err := r.collection.InsertOne(context.TODO(), action)
+	if err != nil {
+		return action, fmt.Errorf("failed to store moderation action: %w", err)
+	}
+
+	action.ID = result.InsertedID.(bson.ObjectID)
+
+	return action, nil
+}
+
+// Resolve atomically moves a pending action that has not expired to the given
+// status, so that a double click cannot run it twice. It returns nil when the
+// action is no longer pending.
+func (r *ModerationRepository) Resolve(id string, status string, resolvedBy string) (*structs.ModerationAction, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": objectID, "status": structs.ModerationPending, "expires_at": bson.M{"$gt": now}}
+	update := bson.M{"$set": bson.M{"status": status, "resolved_by": resolvedBy, "resolved_at": now}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var action structs.ModerationAction
+	err = r.collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&action)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to update moderation action: %w", err)
+	}
+
+	return &action, nil
+}
+
+func (r *ModerationRepository) Fetch(id string) (*structs.ModerationAction, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var action structs.ModerationAction
+	err = r.collection.FindOne(context.TODO(), bson.M{"_id": objectID}).Decode(&action)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch moderation action: %w", err)
+	}
+
+	return &action, nil
+}
+
+// Finish records how a confirmed action went.
+func (r *ModerationRepository) Finish(id bson.ObjectID, actionErr error) error {
+	set := bson.M{"status": structs.ModerationDone}
+	if actionErr != nil {
+		set = bson.M{"status": structs.ModerationFailed, "error": actionErr.Error()}
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update moderation action: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"bot/internal/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+type PrivacyRepository struct {
+	collection *mongo.Collection
+	audit      *mongo.Collection
+	bots       *mongo.Collection
+	reminders  *mongo.Collection
+	usage      *mongo.Collection
+	moderation *mongo.Collection
+}
+
+func NewPrivacyRepository(db *mongo.Database) *PrivacyRepository {
+	return &PrivacyRepository{
+		collection: db.Collection("privacy"),
+		audit:      db.Collection("privacy_audit"),
+		bots:       db.Collection("bots"),
+		reminders:  db.Collection("reminders"),
+		usage:      db.Collection("usage"),
+		moderation: db.Collection("moderation_actions"),
+	}
+}
+
+func (r *PrivacyRepository) IsOptedOut(userID string) (bool, error) {
+	var privacy structs.UserPrivacy
+	filter := bson.M{"user_id": userID}
+	err := r.collection.FindOne(context.TODO(), filter).Decode(&privacy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("mongo find error: %w", err)
+	}
+
+	return privacy.OptedOut, nil
+}
+
+// FilterOptedOut returns the subset of the given user IDs that have opted out.
+func (r *PrivacyRepository) FilterOptedOut(userIDs []string) (map[string]bool, error) {
+	optedOut := make(map[string]bool)
+	if len(userIDs) == 0 {
+		return optedOut, nil
+	}
+
+	filter := bson.M{"user_id": bson.M{"$in": userIDs}, "opted_out": true}
+	cursor, err := r.collection.Find(context.TODO(), filter)
+	if err != nil {
+		return nil, fmt.Errorf("mongo find error: %w", err)
+	}
+
+	var results []structs.UserPrivacy
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, fmt.Errorf("failed to decode privacy settings: %w", err)
+	}
+
+	for _, result := range results {
+		optedOut[result.UserID] = true
+	}
+
+	return optedOut, nil
+}
+
+func (r *PrivacyRepository) SetOptedOut(userID string, optedOut bool) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set": bson.M{
+			"opted_out":  optedOut,
+			"updated_at": time.Now(),
+		},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to update privacy setting: %w", err)
+	}
+
+	return nil
+}
+
+// deletionScopeNote is recorded with every deletion. The bot keeps no archive
+// of conversations, and logs only hold message content when LOG_DEBUG is set,
+// so neither is searched. Turns stored before user IDs were recorded cannot be
+// attributed safely and are left to /memory clear-legacy.
+const deletionScopeNote = "logs and turns stored without a user ID are not covered"
+
+// DeleteUserData removes every conversation turn sent by the user from all
+// guilds. Usage records are kept for the guilds' budgets and moderation actions for
+// the guilds' records, but neither names the user any more.
+// Each request is written to the audit collection whether it succeeds or not.
+func (r *PrivacyRepository) DeleteUserData(userID string, guildID string) (int64, error) {
+	audit := structs.PrivacyAudit{
+		UserID:      userID,
+		Action:      "delete-my-data",
+		GuildID:     guildID,
+		NotCovered:  deletionScopeNote,
+		RequestedAt: time.Now(),
+	}
+
+	affected, err := r.deleteConversations(userID)
+	if err == nil {
+		audit.RemindersDeleted, err = r.deleteReminders(userID)
+	}
+	if err == nil {
+		audit.UsageAnonymized, err = r.anonymizeUsage(userID)
+	}
+	if err == nil {
+		audit.ModerationAnonymized, err = r.anonymizeModeration(userID)
+	}
+
+	audit.BotsAffected = affected
+	audit.CompletedAt = time.Now()
+	if err != nil {
+		audit.Error = err.Error()
+	}
+
+	if _, auditErr := r.audit.InsertOne(context.TODO(), audit); auditErr != nil {
+		slog.Error("Error while writing privacy audit entry", "error", auditErr)
+		if err == nil {
+			err = fmt.Errorf("failed to write audit entry: %w", auditErr)
+		}
+	}
+
+	return affected, err
+}
+
+func (r *PrivacyRepository) deleteConversations(userID string) (int64, error) {
+	result, err := r.bots.UpdateMany(
+		context.TODO(),
+		bson.M{"conversations.user.id": userID},
+		bson.M{"$pull": bson.M{"conversations": bson.M{"user.id": userID}}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete conversations: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *PrivacyRepository) deleteReminders(userID string) (int64, error) {
+	result, err := r.reminders.DeleteMany(context.TODO(), bson.M{"user_id": userID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete reminders: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *PrivacyRepository) anonymizeUsage(userID string) (int64, error) {
+	result, err := r.usage.UpdateMany(context.TODO(), bson.M{"user_id": userID}, bson.M{"$set": bson.M{"user_id": ""}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize usage: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// anonymizeModeration removes the user from moderation actions they requested,
+// were the target of or approved. When they were the target, the name and
+// reason are cleared too, as both usually describe them.
+func (r *PrivacyRepository) anonymizeModeration(userID string) (int64, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"requested_by": userID},
+		bson.M{"target_id": userID},
+		bson.M{"resolved_by": userID},
+	}}
+
+	count, err := r.moderation.CountDocuments(context.TODO(), filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count moderation actions: %w", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	updates := []struct {
+		filter bson.M
+		update bson.M
+	}{
+		{bson.M{"requested_by": userID}, bson.M{"$set": bson.M{"requested_by": ""}}},
+		{bson.M{"target_id": userID}, bson.M{"$unset": bson.M{"target_id": "", "target_name": "", "reason": ""}}},
+		{bson.M{"resolved_by": userID}, bson.M{"$unset": bson.M{"resolved_by": ""}}},
+	}
+
+	for _, u := range updates {
+		_, err := r.moderation.UpdateMany(context.TODO(), u.filter, u.update)
+		if err != nil {
+			return 0, fmt.Errorf("failed to anonymize moderation actions: %w", err)
+		}
+	}
+
+	return count, nil
+}
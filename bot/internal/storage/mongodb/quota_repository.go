@@ -0,0 +1,68 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// QuotaRepository counts daily uses of limited features per guild, keyed by
+// the UTC date.
+type QuotaRepository struct {
+	collection *mongo.Collection
+}
+
+func NewQuotaRepository(db *mongo.Database) *QuotaRepository {
+	return &QuotaRepository{
+		collection: db.Collection("quotas"),
+	}
+}
+
+func quotaDay() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// Reserve takes one use of a feature for today. It returns false without
+// taking anything when the limit has already been reached. The increment is
+// atomic, so concurrent requests cannot go over the limit together.
+func (r *QuotaRepository) Reserve(guildID string, feature string, limit int) (bool, error) {
+	filter := bson.M{"server_id": guildID, "feature": feature, "day": quotaDay()}
+	update := bson.M{"$inc": bson.M{"count": 1}}
+
+	var quota struct {
+		Count int `bson:"count"`
+	}
+
+	err := r.collection.FindOneAndUpdate(
+		context.TODO(),
+		filter,
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&quota)
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve quota: %w", err)
+	}
+
+	if quota.Count > limit {
+		r.Release(guildID, feature)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Release gives back a use taken by Reserve, for when the feature failed.
+func (r *QuotaRepository) Release(guildID string, feature string) {
+	filter := bson.M{"server_id": guildID, "feature": feature, "day": quotaDay(), "count": bson.M{"$gt": 0}}
+	update := bson.M{"$inc": bson.M{"count": -1}}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		slog.Error("Error while releasing quota", "error", err)
+	}
+}
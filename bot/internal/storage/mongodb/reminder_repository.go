@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bot/internal/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MaxPendingReminders caps how many reminders one user can have waiting.
+const MaxPendingReminders = 25
+
+// reminderClaimTimeout is how long a claimed reminder may stay unsent before
+// another scheduler run picks it up again, for when the bot stopped while
+// sending it.
+const reminderClaimTimeout = 5 * time.Minute
+
+type ReminderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReminderRepository(db *mongo.Database) *ReminderRepository {
+	return &ReminderRepository{
+		collection: db.Collection("reminders"),
+	}
+}
+
+func (r *ReminderRepository) Create(reminder structs.Reminder) (structs.Reminder, error) {
+	pending, err := r.collection.CountDocuments(context.TODO(), bson.M{"user_id": reminder.UserID, "status": structs.ReminderPending})
+	if err != nil {
+		return reminder, fmt.Errorf("failed to count reminders: %w", err)
+	}
+	if pending >= MaxPendingReminders {
+		return reminder, fmt.Errorf("you already have %v pending reminders, cancel some first", pending)
+	}
+
+	reminder.CreatedAt = time.Now()
+	reminder.Status = structs.ReminderPending
+
+	result, err := r.collection.InsertOne(context.TODO(), reminder)
+	if err != nil {
+		return reminder, fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	reminder.ID = result.InsertedID.(bson.ObjectID)
+
+	return reminder, nil
+}
+
+// ListPending returns a user's pending reminders in a guild, soonest first.
+func (r *ReminderRepository) ListPending(guildID string, userID string) ([]structs.Reminder, error) {
+	filter := bson.M{"server_id": guildID, "user_id": userID, "status": structs.ReminderPending}
+	opts := options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}})
+
+	cursor, err := r.collection.Find(context.TODO(), filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reminders: %w", err)
+	}
+
+	var reminders []structs.Reminder
+	if err := cursor.All(context.TODO(), &reminders); err != nil {
+		return nil, fmt.Errorf("failed to decode reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// Cancel cancels a pending reminder owned by the user, reporting false when
+// there was no such reminder.
+func (r *ReminderRepository) Cancel(id string, userID string) (bool, error) {
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return false, nil
+	}
+
+	filter := bson.M{"_id": objectID, "user_id": userID, "status": structs.ReminderPending}
+	update := bson.M{"$set": bson.M{"status": structs.ReminderCancelled}}
+
+	result, err := r.collection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel reminder: %w", err)
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// ClaimDue atomically takes one reminder that is due, so that only one
+// scheduler run sends it. It returns nil when nothing is due.
+func (r *ReminderRepository) ClaimDue(now time.Time) (*structs.Reminder, error) {
+	filter := bson.M{
+		"due_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"status": structs.ReminderPending},
+			bson.M{"status": structs.ReminderSending, "claimed_at": bson.M{"$lte": now.Add(-reminderClaimTimeout)}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": structs.ReminderSending, "claimed_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "due_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var reminder structs.Reminder
+	err := r.collection.FindOneAndUpdate(context.TODO(), filter, update, opts).Decode(&reminder)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+
+	return &reminder, nil
+}
+
+func (r *ReminderRepository) SetStatus(id bson.ObjectID, status string) error {
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status}})
+	if err != nil {
+		return fmt.Errorf("failed to update reminder: %w", err)
+	}
+
+	return nil
+}
+
+// Retry puts a reminder that could not be sent back in the queue.
+func (r *ReminderRepository) Retry(id bson.ObjectID, dueAt time.Time) error {
+	update := bson.M{"$set": bson.M{"status": structs.ReminderPending, "due_at": dueAt}}
+
+	_, err := r.collection.UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to reschedule reminder: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"bot/internal/structs"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// UsageRepository keeps token counts per guild, user and UTC day.
+type UsageRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUsageRepository(db *mongo.Database) *UsageRepository {
+	return &UsageRepository{
+		collection: db.Collection("usage"),
+	}
+}
+
+func usageMonth() string {
+	return time.Now().UTC().Format("2006-01")
+}
+
+func (r *UsageRepository) Record(guildID string, userID string, usage structs.TokenUsage) error {
+	filter := bson.M{"server_id": guildID, "user_id": userID, "day": quotaDay()}
+	update := bson.M{
+		"$inc": bson.M{
+			"requests":         usage.Requests,
+			"prompt_tokens":    usage.Prompt,
+			"candidate_tokens": usage.Candidates,
+			"tool_tokens":      usage.Tool,
+			"thoughts_tokens":  usage.Thoughts,
+			"total_tokens":     usage.Total,
+		},
+		"$setOnInsert": bson.M{"month": usageMonth()},
+	}
+
+	_, err := r.collection.UpdateOne(context.TODO(), filter, update, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+
+	return nil
+}
+
+// usageGroup adds up the counters of the matched documents, grouped by id.
+func usageGroup(id any) bson.M {
+	return bson.M{
+		"_id":              id,
+		"requests":         bson.M{"$sum": "$requests"},
+		"prompt_tokens":    bson.M{"$sum": "$prompt_tokens"},
+		"candidate_tokens": bson.M{"$sum": "$candidate_tokens"},
+		"tool_tokens":      bson.M{"$sum": "$tool_tokens"},
+		"thoughts_tokens":  bson.M{"$sum": "$thoughts_tokens"},
+		"total_tokens":     bson.M{"$sum": "$total_tokens"},
+	}
+}
+
+// Totals returns the guild's usage for the current UTC day and month.
+func (r *UsageRepository) Totals(guildID string) (structs.TokenUsage, structs.TokenUsage, error) {
+	today, err := r.sum(bson.M{"server_id": guildID, "day": quotaDay()})
+	if err != nil {
+		return today, structs.TokenUsage{}, err
+	}
+
+	month, err := r.sum(bson.M{"server_id": guildID, "month": usageMonth()})
+
+	return today, month, err
+}
+
+func (r *UsageRepository) sum(filter bson.M) (structs.TokenUsage, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: usageGroup(nil)}},
+	}
+
+	cursor, err := r.collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return structs.TokenUsage{}, fmt.Errorf("failed to sum usage: %w", err)
+	}
+
+	var results []structs.TokenUsage
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return structs.TokenUsage{}, fmt.Errorf("failed to decode usage: %w", err)
+	}
+	if len(results) == 0 {
+		return structs.TokenUsage{}, nil
+	}
+
+	return results[0], nil
+}
+
+// TopUsers returns the users who spent the most tokens this month, most
+// first. Usage of users who deleted their data is left out.
+func (r *UsageRepository) TopUsers(guildID string, limit int) ([]structs.UserUsage, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"server_id": guildID, "month": usageMonth(), "user_id": bson.M{"$ne": ""}}}},
+		{{Key: "$group", Value: usageGroup("$user_id")}},
+		{{Key: "$sort", Value: bson.M{"total_tokens": -1}}},
+		{{Key: "$limit", Value: limit}},
+	}
+
+	cursor, err := r.collection.Aggregate(context.TODO(), pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch top users: %w", err)
+	}
+
+	var users []structs.UserUsage
+	if err := cursor.All(context.TODO(), &users); err != nil {
+		return nil, fmt.Errorf("failed to decode usage: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,10 @@
+package structs
+
+// Attachment describes a file that was sent to the model along with a
+// message. Only the metadata is stored, not the file itself.
+type Attachment struct {
+	Name        string `bson:"name" json:"name"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	Size        int    `bson:"size" json:"size"`
+	Source      string `bson:"source" json:"source"`
+}
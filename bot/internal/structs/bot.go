@@ -1,23 +1,41 @@
 package structs
 
 type User struct {
-	Name    string `bson:"name"`
-	Message string `bson:"message"`
+	ID          string       `bson:"id,omitempty" json:"-"`
+	Name        string       `bson:"name"`
+	Message     string       `bson:"message"`
+	Attachments []Attachment `bson:"attachments,omitempty" json:",omitempty"`
 }
 
 type Conversation struct {
-	User User   `bson:"user"`
-	Bot  string `bson:"bot"`
+	// ID is the Discord message ID of the bot's reply, used to find the entry
+	// again from the reply's action buttons.
+	ID        string   `bson:"id,omitempty" json:"-"`
+	ChannelID string   `bson:"channel_id,omitempty" json:"-"`
+	RequestID string   `bson:"request_id,omitempty" json:"-"`
+	FollowUps []string `bson:"follow_ups,omitempty" json:"-"`
+	User      User     `bson:"user"`
+	Bot       string   `bson:"bot"`
 }
 
 type Bot struct {
-	Name              string         `bson:"name"`
-	Persona           string         `bson:"persona"`
-	ServerID          string         `bson:"server_id"`
-	UserID            string         `bson:"user_id"`
-	GoogleAIAPI       EncryptedAPI   `bson:"google_ai_api"`
-	OpenWeatherMapAPI EncryptedAPI   `bson:"openweathermap_api"`
-	VyntrAPI          EncryptedAPI   `bson:"vyntr_api"`
-	Image             string         `bson:"image_id"`
-	Conversations     []Conversation `bson:"conversations"`
+	Name              string          `bson:"name"`
+	Persona           string          `bson:"persona"`
+	ServerID          string          `bson:"server_id"`
+	UserID            string          `bson:"user_id"`
+	GoogleAIAPI       EncryptedAPI    `bson:"google_ai_api"`
+	OpenWeatherMapAPI EncryptedAPI    `bson:"openweathermap_api"`
+	VyntrAPI          EncryptedAPI    `bson:"vyntr_api"`
+	BraveAPI          EncryptedAPI    `bson:"brave_api"`
+	SearchProvider    string          `bson:"search_provider"`
+	PrefetchLinks     bool            `bson:"prefetch_links"`
+	Image             string          `bson:"image_id"`
+	Conversations     []Conversation  `bson:"conversations"`
+	Policy            GuildPolicy     `bson:"policy"`
+	ContentPolicy     ContentPolicy   `bson:"content_policy"`
+	ImageGeneration   ImageGeneration `bson:"image_generation"`
+	ModLogChannel     string          `bson:"mod_log_channel"`
+	InputScreening    InputScreening  `bson:"input_screening"`
+	OutputFilter      OutputFilter    `bson:"output_filter"`
+	UsageBudget       UsageBudget     `bson:"usage_budget"`
 }
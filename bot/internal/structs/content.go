@@ -0,0 +1,11 @@
+package structs
+
+// ContentPolicy controls which image categories a guild allows. Categories in
+// NSFWOnlyCategories are only posted in age-restricted channels, and ones in
+// EnabledCategories everywhere. Categories in none of the lists are allowed
+// everywhere only when they are known to be safe.
+type ContentPolicy struct {
+	EnabledCategories  []string `bson:"enabled_categories,omitempty"`
+	DisabledCategories []string `bson:"disabled_categories,omitempty"`
+	NSFWOnlyCategories []string `bson:"nsfw_only_categories,omitempty"`
+}
@@ -0,0 +1,12 @@
+package structs
+
+// ImageGeneration controls the generateImage tool for a guild. It is off by
+// default since every image is paid for with the guild's own key.
+type ImageGeneration struct {
+	Enabled    bool `bson:"enabled"`
+	DailyLimit int  `bson:"daily_limit"`
+}
+
+// DefaultImageDailyLimit applies when a guild enabled image generation
+// without choosing a limit.
+const DefaultImageDailyLimit = 10
@@ -0,0 +1,45 @@
+package structs
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	ModerationTimeout        = "timeout"
+	ModerationDeleteMessages = "delete-messages"
+	ModerationAddRole        = "add-role"
+)
+
+const (
+	ModerationPending   = "pending"
+	ModerationExecuting = "executing"
+	ModerationDone      = "done"
+	ModerationCancelled = "cancelled"
+	ModerationFailed    = "failed"
+)
+
+// ModerationAction is an action the AI proposed on behalf of a moderator. It
+// only runs once the moderator confirms it.
+type ModerationAction struct {
+	ID          bson.ObjectID `bson:"_id,omitempty"`
+	GuildID     string        `bson:"server_id"`
+	ChannelID   string        `bson:"channel_id"`
+	RequestID   string        `bson:"request_id"`
+	RequestedBy string        `bson:"requested_by"`
+	Action      string        `bson:"action"`
+	TargetID    string        `bson:"target_id,omitempty"`
+	TargetName  string        `bson:"target_name,omitempty"`
+	RoleID      string        `bson:"role_id,omitempty"`
+	RoleName    string        `bson:"role_name,omitempty"`
+	Minutes     int           `bson:"minutes,omitempty"`
+	Count       int           `bson:"count,omitempty"`
+	Reason      string        `bson:"reason,omitempty"`
+	Status      string        `bson:"status"`
+	Error       string        `bson:"error,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at"`
+	ExpiresAt   time.Time     `bson:"expires_at"`
+	ResolvedBy  string        `bson:"resolved_by,omitempty"`
+	ResolvedAt  time.Time     `bson:"resolved_at,omitempty"`
+}
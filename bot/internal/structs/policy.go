@@ -0,0 +1,10 @@
+package structs
+
+// GuildPolicy limits who can use the bot and where. Empty lists place no
+// restriction, so a guild without a policy behaves as before.
+type GuildPolicy struct {
+	AllowedRoles    []string `bson:"allowed_roles,omitempty"`
+	DeniedRoles     []string `bson:"denied_roles,omitempty"`
+	AllowedChannels []string `bson:"allowed_channels,omitempty"`
+	AdminCommands   []string `bson:"admin_commands,omitempty"`
+}
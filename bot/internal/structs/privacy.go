@@ -0,0 +1,25 @@
+package structs
+
+import "time"
+
+type UserPrivacy struct {
+	UserID    string    `bson:"user_id"`
+	OptedOut  bool      `bson:"opted_out"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// PrivacyAudit records a data deletion request and what it removed, without
+// keeping any of the removed content.
+type PrivacyAudit struct {
+	UserID               string    `bson:"user_id"`
+	Action               string    `bson:"action"`
+	GuildID              string    `bson:"guild_id,omitempty"`
+	BotsAffected         int64     `bson:"bots_affected"`
+	RemindersDeleted     int64     `bson:"reminders_deleted"`
+	UsageAnonymized      int64     `bson:"usage_anonymized"`
+	ModerationAnonymized int64     `bson:"moderation_anonymized"`
+	NotCovered           string    `bson:"not_covered,omitempty"`
+	RequestedAt          time.Time `bson:"requested_at"`
+	CompletedAt          time.Time `bson:"completed_at"`
+	Error                string    `bson:"error,omitempty"`
+}
@@ -0,0 +1,28 @@
+package structs
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	ReminderPending   = "pending"
+	ReminderSending   = "sending"
+	ReminderSent      = "sent"
+	ReminderCancelled = "cancelled"
+	ReminderFailed    = "failed"
+)
+
+type Reminder struct {
+	ID        bson.ObjectID `bson:"_id,omitempty"`
+	GuildID   string        `bson:"server_id"`
+	ChannelID string        `bson:"channel_id"`
+	UserID    string        `bson:"user_id"`
+	Message   string        `bson:"message"`
+	DueAt     time.Time     `bson:"due_at"`
+	CreatedAt time.Time     `bson:"created_at"`
+	Status    string        `bson:"status"`
+	ClaimedAt time.Time     `bson:"claimed_at,omitempty"`
+	Attempts  int           `bson:"attempts"`
+}
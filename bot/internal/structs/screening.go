@@ -0,0 +1,31 @@
+package structs
+
+// InputScreening configures the checks a message goes through before it is
+// sent to the model. Every check is off by default.
+type InputScreening struct {
+	// InjectionFilter blocks messages that try to pass themselves off as
+	// instructions to the model.
+	InjectionFilter bool     `bson:"injection_filter"`
+	BlockedWords    []string `bson:"blocked_words,omitempty"`
+	BlockedPatterns []string `bson:"blocked_patterns,omitempty"`
+
+	// SafetyThreshold classifies messages with Gemini's safety filters at
+	// the given level when set.
+	SafetyThreshold string `bson:"safety_threshold,omitempty"`
+
+	// BlockedReply is posted instead of an answer when a message is blocked.
+	BlockedReply string `bson:"blocked_reply,omitempty"`
+}
+
+const DefaultBlockedReply = "Sorry, I can't respond to that message."
+
+// OutputFilter configures how AI replies are cleaned up before they are
+// posted. Invites and mass mentions are filtered unless a guild allows them,
+// and words blocked for input are filtered from replies too.
+type OutputFilter struct {
+	AllowInvites      bool `bson:"allow_invites"`
+	AllowMassMentions bool `bson:"allow_mass_mentions"`
+
+	// Mode is "neutralize" or "strip", neutralize when empty.
+	Mode string `bson:"mode,omitempty"`
+}
@@ -0,0 +1,40 @@
+package structs
+
+type FetchedVyntrSearch struct {
+	Web []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Preview string `json:"preview"`
+	} `json:"web"`
+	Bliptext *struct {
+		Title   string `json:"title"`
+		Summary string `json:"summary"`
+		URL     string `json:"url"`
+	} `json:"bliptext"`
+}
+
+type FetchedSearXNGSearch struct {
+	Results []struct {
+		Title   string `json:"title"`
+		URL     string `json:"url"`
+		Content string `json:"content"`
+	} `json:"results"`
+}
+
+type FetchedBraveSearch struct {
+	Web struct {
+		Results []struct {
+			Title         string   `json:"title"`
+			URL           string   `json:"url"`
+			Description   string   `json:"description"`
+			ExtraSnippets []string `json:"extra_snippets"`
+		} `json:"results"`
+	} `json:"web"`
+}
+
+// SearchResult is one web search result as given to the model.
+type SearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Snippet string `json:"snippet"`
+}
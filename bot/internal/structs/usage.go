@@ -0,0 +1,33 @@
+package structs
+
+// TokenUsage counts the requests and tokens spent on a guild's Gemini key.
+type TokenUsage struct {
+	Requests   int64 `bson:"requests"`
+	Prompt     int64 `bson:"prompt_tokens"`
+	Candidates int64 `bson:"candidate_tokens"`
+	Tool       int64 `bson:"tool_tokens"`
+	Thoughts   int64 `bson:"thoughts_tokens"`
+	Total      int64 `bson:"total_tokens"`
+}
+
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.Requests += other.Requests
+	u.Prompt += other.Prompt
+	u.Candidates += other.Candidates
+	u.Tool += other.Tool
+	u.Thoughts += other.Thoughts
+	u.Total += other.Total
+}
+
+// UserUsage is one user's share of a guild's usage.
+type UserUsage struct {
+	UserID string     `bson:"_id"`
+	Usage  TokenUsage `bson:",inline"`
+}
+
+// UsageBudget limits the tokens a guild spends per UTC day and month. Zero
+// means no limit.
+type UsageBudget struct {
+	DailyTokens   int64 `bson:"daily_tokens"`
+	MonthlyTokens int64 `bson:"monthly_tokens"`
+}
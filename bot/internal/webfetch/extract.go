@@ -0,0 +1,129 @@
+package webfetch
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements hold scripts, styling and page furniture rather than the
+// text a reader came for.
+var skippedElements = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Nav:      true,
+	atom.Header:   true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Select:   true,
+}
+
+// blockElements start a new line in the extracted text.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Article: true, atom.Section: true, atom.Main: true, atom.Blockquote: true,
+	atom.Pre: true, atom.Table: true, atom.Ul: true, atom.Ol: true, atom.Dd: true, atom.Dt: true,
+}
+
+// minMainText is how much text an article or main element needs before it is
+// preferred over the whole page.
+const minMainText = 200
+
+// ExtractText reads the title and readable text of an HTML page. When the page
+// marks its content with an article or main element, only that is returned.
+func ExtractText(r io.Reader) (string, string, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	var title string
+	var main *html.Node
+
+	var find func(node *html.Node)
+	find = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			switch node.DataAtom {
+			case atom.Title:
+				if title == "" && node.FirstChild != nil {
+					title = collapseSpaces(node.FirstChild.Data)
+				}
+			case atom.Article, atom.Main:
+				if main == nil {
+					main = node
+				}
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			find(child)
+		}
+	}
+	find(doc)
+
+	if main != nil {
+		if text := nodeText(main); len(text) >= minMainText {
+			return title, text, nil
+		}
+	}
+
+	return title, nodeText(doc), nil
+}
+
+func nodeText(root *html.Node) string {
+	var builder strings.Builder
+
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			builder.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if skippedElements[node.DataAtom] {
+				return
+			}
+			if blockElements[node.DataAtom] {
+				builder.WriteString("\n")
+			}
+		}
+
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+
+		if node.Type == html.ElementNode && blockElements[node.DataAtom] {
+			builder.WriteString("\n")
+		}
+	}
+	walk(root)
+
+	return CleanText(builder.String())
+}
+
+// CleanText collapses runs of spaces within lines and drops empty lines.
+func CleanText(text string) string {
+	lines := strings.Split(text, "\n")
+	cleaned := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if line = collapseSpaces(line); line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+
+	return strings.Join(cleaned, "\n")
+}
+
+func collapseSpaces(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
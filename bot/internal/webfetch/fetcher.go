@@ -0,0 +1,182 @@
+package webfetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxRedirects is how many redirects a fetch follows. Every hop goes through
+// the same address checks.
+const maxRedirects = 5
+
+type Fetcher struct {
+	Client   *http.Client
+	Timeout  time.Duration
+	MaxBytes int64
+
+	// MaxText is the longest excerpt returned, in characters.
+	MaxText int
+}
+
+// Page is the readable text of a fetched page. Truncated is set when the
+// excerpt was cut short, either by MaxBytes or MaxText.
+type Page struct {
+	URL         string
+	Title       string
+	Text        string
+	ContentType string
+	Truncated   bool
+}
+
+func NewFetcher() *Fetcher {
+	timeout := 10 * time.Second
+
+	transport := &http.Transport{
+		// No proxy, so that connections are always checked by the dialer.
+		Proxy:                 nil,
+		DialContext:           guardedDialContext(timeout),
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %v redirects", maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+
+	return &Fetcher{
+		Client:   client,
+		Timeout:  timeout,
+		MaxBytes: 2 << 20,
+		MaxText:  8000,
+	}
+}
+
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("only http and https links can be read")
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("the link has no host")
+	}
+
+	return nil
+}
+
+// Fetch downloads an HTML or text page and returns a cleaned excerpt of its
+// text.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Page, error) {
+	page := Page{URL: rawURL}
+
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return page, fmt.Errorf("invalid link: %v", err)
+	}
+	if err := checkURL(parsed); err != nil {
+		return page, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return page, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; CordfriendAI)")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return page, fmt.Errorf("failed to fetch page: %v", err)
+	}
+	defer resp.Body.Close()
+
+	page.URL = resp.Request.URL.String()
+
+	if resp.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("failed to fetch page: status %v", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	page.ContentType = mediaType
+
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	if !isHTML && !strings.HasPrefix(mediaType, "text/") {
+		return page, fmt.Errorf("cannot read pages of type '%v'", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.MaxBytes+1), contentType)
+	if err != nil {
+		return page, fmt.Errorf("unsupported character set: %v", err)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return page, fmt.Errorf("failed to read page: %v", err)
+	}
+	if int64(len(data)) > f.MaxBytes {
+		data = data[:f.MaxBytes]
+		page.Truncated = true
+	}
+
+	if isHTML {
+		page.Title, page.Text, err = ExtractText(strings.NewReader(string(data)))
+		if err != nil {
+			return page, fmt.Errorf("failed to read page: %v", err)
+		}
+	} else {
+		page.Text = CleanText(strings.ToValidUTF8(string(data), ""))
+	}
+
+	if utf8.RuneCountInString(page.Text) > f.MaxText {
+		page.Text = string([]rune(page.Text)[:f.MaxText])
+		page.Truncated = true
+	}
+
+	if page.Text == "" {
+		return page, fmt.Errorf("the page has no readable text")
+	}
+
+	return page, nil
+}
+
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"'|]+`)
+
+// Links returns up to limit distinct http and https links found in a message,
+// in the order they appear. Links wrapped in angle brackets are included,
+// since Discord users do that to hide the preview.
+func Links(message string, limit int) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	for _, link := range linkPattern.FindAllString(message, -1) {
+		link = strings.TrimRight(link, ".,!?)]*_~")
+		if seen[link] || len(links) == limit {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+
+	return links
+}
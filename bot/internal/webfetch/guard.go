@@ -0,0 +1,76 @@
+package webfetch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// blockedPrefixes are the ranges pages may not be fetched from, so the tool
+// cannot be used to reach the bot's host or its private network. Loopback,
+// private, link-local and multicast addresses are checked separately.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// CheckAddr reports an error when the address is not a public unicast
+// address.
+func CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return fmt.Errorf("address %v is not public", addr)
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("address %v is not public", addr)
+		}
+	}
+
+	return nil
+}
+
+// guardedDialer checks the address every connection is made to, after DNS
+// resolution, so a host name that resolves to a private address is refused
+// even when it changes between lookups.
+func guardedDialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return fmt.Errorf("invalid address %v", host)
+			}
+
+			return CheckAddr(addr)
+		},
+	}
+}
+
+func guardedDialContext(timeout time.Duration) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	dialer := guardedDialer(timeout)
+
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, address)
+	}
+}
@@ -551,6 +551,7 @@ connectToMongodb().then(() => {
 			'openweathermap_api': encryptApiKey(botData.openweathermap_api),
 			'vyntr_api': encryptApiKey(botData.vyntr_api),
 			'vyntr_api': encryptApiKey(botData.vyntr_api),
+			'brave_api': encryptApiKey(botData.brave_api || ''),
 			'image_id': botData.image_id,
 			'image_filename': botData.image_filename
 		};
@@ -638,6 +639,9 @@ connectToMongodb().then(() => {
 					// Decrypt the Vyntr API key
 					fetchedBot.vyntr_api = decryptApiKey(fetchedBot.vyntr_api);
 
+					// Decrypt the Brave Search API key, which older bots do not have
+					fetchedBot.brave_api = fetchedBot.brave_api ? decryptApiKey(fetchedBot.brave_api) : '';
+
 					console.log('Fetched bot:', fetchedBot);
 					return fetchedBot;
 				})
@@ -686,6 +690,9 @@ connectToMongodb().then(() => {
 			// Decrypt the Vyntr API key
 			fetchedBot.vyntr_api = decryptApiKey(fetchedBot.vyntr_api);
 
+			// Decrypt the Brave Search API key, which older bots do not have
+			fetchedBot.brave_api = fetchedBot.brave_api ? decryptApiKey(fetchedBot.brave_api) : '';
+
 			console.log('Fetched bot:', fetchedBot);
 
 			if (!fetchedBot) {
@@ -718,6 +725,9 @@ connectToMongodb().then(() => {
 		// Encrypt the Vyntr API key
 		botData.vyntr_api = encryptApiKey(botData.vyntr_api);
 
+		// Encrypt the Brave Search API key
+		botData.brave_api = encryptApiKey(botData.brave_api || '');
+
 		if (!botData) {
 			return sendErrorResponse(res, 400, 'No bot data provided.');
 		}
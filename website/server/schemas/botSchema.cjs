@@ -9,6 +9,7 @@ const botUpdateSchema = Joi.object({
     google_ai_api: Joi.string().required(),
     openweathermap_api: Joi.string().required(),
     vyntr_api: Joi.string().required(),
+    brave_api: Joi.string().allow('').optional(),
     image_id: Joi.string().length(24).hex().required(),
     image_filename: Joi.string().required(),
     old_image_id: Joi.string().length(24).hex().optional(),